@@ -1,10 +1,13 @@
 package converter
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"math"
+	"math/big"
+	"sort"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
@@ -12,217 +15,310 @@ type Result struct {
 	Value      float64
 	UnitSymbol string
 	UnitName   string
-}
-
-type compiledRegexes struct {
-	targetUnit *regexp.Regexp
-	component  *regexp.Regexp
-	fraction   *regexp.Regexp
+	// Exact is the value as an exact fraction. It is only set in exact mode,
+	// and only when every unit involved is defined by an exact factor, so
+	// never for units defined through π, such as degrees.
+	Exact *big.Rat
+	// RatesAsOf is the date of the exchange rates a currency conversion
+	// used, and the zero time for every other conversion.
+	RatesAsOf time.Time
 }
 
 type Converter struct {
-	unitMap map[string]Unit
-	regexes compiledRegexes
+	registry *Registry
+	exact    bool
+	locale   Locale
+	dpi      *big.Rat
+	fontSize *big.Rat
 }
 
-type parsedComponent struct {
-	Value    float64
-	Unit     Unit
-	Operator string
+func NewConverter(registry *Registry) *Converter {
+	return &Converter{registry: registry, locale: LocaleUS, dpi: defaultDPI, fontSize: defaultFontSize}
 }
 
-var textNumberMap = map[string]string{
-	"a": "1", "an": "1", "one": "1", "two": "2", "three": "3", "four": "4", "five": "5",
-	"six": "6", "seven": "7", "eight": "8", "nine": "9", "ten": "10", "half": "0.5",
+// Registry returns the catalog of units the converter understands.
+func (c *Converter) Registry() *Registry {
+	return c.registry
 }
 
-func NewConverter(unitMap map[string]Unit) *Converter {
-	numberRegexPart := `((?:\d+(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d+)?)`
-	unitRegexPart := `([a-z][a-z0-9^³²\/]*)`
+// SetExact turns exact rational arithmetic on or off. In exact mode Process
+// also reports Result.Exact, computed from the units' defined factors.
+func (c *Converter) SetExact(exact bool) {
+	c.exact = exact
+}
 
-	regexes := compiledRegexes{
-		targetUnit: regexp.MustCompile(`\s+(?:in|to)\s+([a-z0-9\s^³²\/]+)$`),
-		component:  regexp.MustCompile(fmt.Sprintf(`\s*([+\-*\/])?\s*%s?\s*%s`, numberRegexPart, unitRegexPart)),
-		fraction:   regexp.MustCompile(`(\d+)\s*/\s*(\d+)`),
+// Process evaluates an expression such as "5 ft 3 in + 2 cm in mm". The
+// input is tokenized and parsed into a tree first; units are then looked up
+// and disambiguated against each other and the target before the tree is
+// evaluated.
+func (c *Converter) Process(input string) (*Result, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
 	}
-
-	return &Converter{
-		unitMap: unitMap,
-		regexes: regexes,
+	tokens, settings := splitSettings(tokens)
+	if c, err = c.withSettings(settings); err != nil {
+		return nil, err
 	}
-}
-
-func (c *Converter) preprocessInput(input string) string {
-	clean := strings.ToLower(input)
-
-	// Remove "convert" prefix if present
-	clean = regexp.MustCompile(`^\s*convert\s+`).ReplaceAllString(clean, "")
-
-	for word, numStr := range textNumberMap {
-		re := regexp.MustCompile(`\b` + word + `\b`)
-		clean = re.ReplaceAllString(clean, numStr)
+	tokens, targetTokens := splitTarget(tokens)
+	if len(tokens) == 0 {
+		return nil, &EmptyExpressionError{Input: input}
 	}
-
-	clean = strings.ReplaceAll(clean, " and ", " + ")
-
-	clean = c.regexes.fraction.ReplaceAllStringFunc(clean, func(m string) string {
-		parts := c.regexes.fraction.FindStringSubmatch(m)
-		if len(parts) < 3 {
-			return m
+	if len(targetTokens) == 0 {
+		if err := missingTarget(tokens); err != nil {
+			return nil, err
 		}
-		num, _ := strconv.ParseFloat(parts[1], 64)
-		den, _ := strconv.ParseFloat(parts[2], 64)
-		if den == 0 {
-			return "0"
-		}
-		return fmt.Sprintf("%f", num/den)
-	})
-
-	return clean
-}
-
-func (c *Converter) Process(input string) (*Result, error) {
-	cleanInput := c.preprocessInput(input)
-
-	targetMatch := c.regexes.targetUnit.FindStringSubmatch(cleanInput)
-	var targetUnit *Unit
-	if targetMatch != nil {
-		cleanInput = strings.TrimSpace(c.regexes.targetUnit.ReplaceAllString(cleanInput, ""))
-		targetUnitStr := strings.TrimSpace(targetMatch[1])
-		unit, ok := c.findUnit(targetUnitStr)
-		if !ok {
-			return nil, c.createNotFoundError(targetUnitStr)
-		}
-		targetUnit = &unit
 	}
-
-	matches := c.regexes.component.FindAllStringSubmatch(cleanInput, -1)
-	if len(matches) == 0 {
-		return nil, fmt.Errorf("no valid units found in input: '%s'", input)
+	tree, err := parse(tokens)
+	if err != nil {
+		return nil, err
 	}
 
-	var components []parsedComponent
-	var lastParsedUnit Unit
-	for _, match := range matches {
-		signStr := match[1]
-		valueStr := match[2]
-		unitStr := match[3]
-
-		if unitStr == "and" || unitStr == "&" {
-			continue
+	r := c.newResolver()
+	if err := r.lookup(tree); err != nil {
+		return nil, err
+	}
+	var target *quantityNode
+	// targetPos is where errors about the unit of the result point to
+	targetPos := tree.pos()
+	if len(targetTokens) > 0 {
+		at := targetTokens[0].Pos
+		targetPos = at
+		target = &quantityNode{value: big.NewRat(1, 1), unit: joinTokens(targetTokens), at: at, unitAt: at}
+		if err := r.lookup(target); err != nil {
+			return nil, err
 		}
+		// The target must have the dimension of the expression, so "c" is a
+		// temperature in "100 c in f" rather than a cup.
+		if common := r.dimensions(tree).intersect(r.dimensions(target)); len(common) > 0 {
+			r.constrain(tree, common)
+			r.constrain(target, common)
+		}
+	}
+	if err := r.choose(); err != nil {
+		return nil, err
+	}
 
-		unit, ok := c.findUnit(unitStr)
-		if !ok {
-			return nil, c.createNotFoundError(unitStr)
+	total, err := r.evaluate(tree)
+	if err != nil {
+		return nil, err
+	}
+	if total.points != 0 && total.points != 1 {
+		message := "cannot add absolute temperatures; add a difference such as Δ°C or subtract them"
+		if total.unit != nil && total.unit.Affine && total.unit.Delta == "" {
+			message = fmt.Sprintf("cannot add gauge %[1]ss; add an absolute %[1]s or subtract them", describe(total.dim, total.kind))
 		}
+		return nil, &AbsoluteValueError{Message: message, Position: tree.pos()}
+	}
 
-		value := 1.0
-		if valueStr != "" {
-			var err error
-			value, err = strconv.ParseFloat(valueStr, 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid number: '%s'", valueStr)
+	var targetUnit *Unit
+	switch {
+	case target != nil:
+		unit := r.units[target]
+		targetUnit = &unit
+	case total.dim == Dimensionless && (total.unit == nil || total.unit.Dimension != total.dim):
+		// "10 m / 2 m" is a plain number
+		return r.dated(c.result(total, Unit{Factor: big.NewRat(1, 1)}.withFactorFuncs()))
+	case total.unit != nil && total.unit.Dimension == total.dim:
+		targetUnit = total.unit
+		// "30 psig - 10 psig" is a difference, read in the gauge's own scale
+		if total.points == 0 && total.unit.Affine && total.unit.Delta == "" {
+			if unit, ok := c.registry.absoluteUnit(*total.unit); ok {
+				targetUnit = &unit
 			}
 		}
-
-		if signStr == "-" {
-			value = -value
+	default:
+		// "10 km / 2 hr" has no target; express it in the unit it was built from.
+		targetUnit = &Unit{Symbol: total.label, Dimension: total.dim}
+		if candidates, ok := c.findUnit(total.label); ok {
+			for i := range candidates {
+				if candidates[i].Dimension == total.dim {
+					targetUnit = &candidates[i]
+					break
+				}
+			}
 		}
-
-		// Initial component is additive
-		if len(components) == 0 && signStr == "" {
-			signStr = "+"
+		// A coherent compound is its SI unit: "2 A * 5 V" is 10 W
+		if _, registered := c.registry.Lookup(total.label); !registered && isOne(targetUnit.Factor) {
+			if unit, ok := c.registry.coherentUnit(total.dim); ok {
+				targetUnit = &unit
+			}
 		}
-
-		// Subsequent components are additive if no sign is specified
-		if len(components) > 0 && signStr == "" {
-			signStr = "+"
+	}
+	// "2 cups of sugar in g" weighs the sugar
+	if targetUnit.Dimension != total.dim {
+		if measured, ok := total.measuredAs(targetUnit.Dimension); ok {
+			total = measured
 		}
-
-		components = append(components, parsedComponent{Value: value, Unit: unit, Operator: signStr})
-		lastParsedUnit = unit
 	}
-
-	if targetUnit == nil {
-		if len(components) == 0 {
-			return nil, fmt.Errorf("no processable components found in input: '%s'", input)
+	if targetUnit.Dimension != total.dim || kindsDiffer(total.kind, targetUnit.Kind) || targetUnit.ToBaseFunc == nil {
+		return nil, &IncompatibleUnitsError{
+			From:          total.name(),
+			FromDimension: total.dim,
+			FromKind:      total.kind,
+			To:            targetUnit.Symbol,
+			ToDimension:   targetUnit.Dimension,
+			ToKind:        targetUnit.Kind,
 		}
-		targetUnit = &lastParsedUnit
 	}
 
-	totalInBase := 0.0
-	for _, comp := range components {
-		valInBase := comp.Unit.ToBaseFunc(comp.Value)
-		switch comp.Operator {
-		case "+":
-			totalInBase += valInBase
-		case "-":
-			totalInBase -= valInBase
-		case "*":
-			totalInBase *= valInBase
-		case "/":
-			if valInBase == 0 {
-				return nil, fmt.Errorf("division by zero")
+	// "30 c - 10 c in f" is a difference, so it is reported in Δ°F. A gauge
+	// has no difference unit; "14.7 psi in psig" reads the absolute pressure
+	// on the gauge.
+	if total.points == 0 && targetUnit.Affine && targetUnit.Delta != "" {
+		candidates, ok := c.findUnit(targetUnit.Delta)
+		if !ok {
+			return nil, &AbsoluteValueError{
+				Message:  fmt.Sprintf("cannot express a temperature difference in '%s'", targetUnit.Symbol),
+				Position: targetPos,
 			}
-			totalInBase /= valInBase
+		}
+		targetUnit = &candidates[0]
+	}
+	if total.points == 1 && !targetUnit.Affine && total.unit.Delta != "" {
+		return nil, &AbsoluteValueError{
+			Message:  fmt.Sprintf("cannot express an absolute temperature in '%s'", targetUnit.Symbol),
+			Position: targetPos,
 		}
 	}
 
-	finalValue := targetUnit.FromBaseFunc(totalInBase)
+	return r.dated(c.result(total, *targetUnit))
+}
 
-	return &Result{
-		Value:      finalValue,
-		UnitSymbol: targetUnit.Symbol,
-		UnitName:   targetUnit.Name,
-	}, nil
+// result expresses a total in the target unit.
+func (c *Converter) result(total quantity, target Unit) (*Result, error) {
+	result := &Result{
+		Value:      target.FromBaseFunc(total.value),
+		UnitSymbol: target.Symbol,
+		UnitName:   target.Name,
+	}
+	// A unit without a Factor may have no value for zero: 0 mpg in L/100km
+	if math.IsInf(result.Value, 0) || math.IsNaN(result.Value) {
+		return nil, &InfiniteResultError{Quantity: "zero " + describe(total.dim, total.kind), Unit: target.Symbol, Position: total.at}
+	}
+	if total.exact != nil {
+		if exact, ok := target.fromBaseExact(total.exact); ok {
+			// Report the float nearest to the exact value
+			result.Value, _ = exact.Float64()
+			if c.exact && !total.inexact && !target.Inexact {
+				result.Exact = exact
+			}
+		}
+	}
+	return result, nil
 }
 
-func (c *Converter) findUnit(s string) (Unit, bool) {
+// findUnit returns every unit the string could refer to, whether it is
+// registered or a compound such as "m/s" or "kg*m/s^2".
+func (c *Converter) findUnit(s string) ([]Unit, bool) {
 	s = strings.TrimSpace(s)
-	unit, ok := c.unitMap[strings.ToLower(s)]
-	if ok {
-		return unit, true
+	if units, ok := c.lookup(s); ok {
+		return units, true
 	}
+	units, err := parseUnitExpr(s, c.lookup)
+	return units, err == nil
+}
 
-	// Handle compound units like "m/s"
-	parts := strings.Split(s, "/")
-	if len(parts) == 2 {
-		numerator, ok1 := c.findUnit(parts[0])
-		denominator, ok2 := c.findUnit(parts[1])
-		if ok1 && ok2 {
-			return Unit{
-				Name:         fmt.Sprintf("%s per %s", numerator.Name, denominator.Name),
-				Symbol:       fmt.Sprintf("%s/%s", numerator.Symbol, denominator.Symbol),
-				ToBaseFunc:   func(val float64) float64 { return numerator.ToBaseFunc(val) / denominator.ToBaseFunc(1) },
-				FromBaseFunc: func(val float64) float64 { return numerator.FromBaseFunc(val) * denominator.ToBaseFunc(1) },
-			}, true
+// unknownUnitError suggests up to three units whose aliases are close to the
+// unknown one. A power of known units that is out of range, such as "m^200",
+// is reported as such instead.
+func (c *Converter) unknownUnitError(unknownUnit string, pos int) error {
+	var rangeErr *ExponentRangeError
+	if _, err := parseUnitExpr(unknownUnit, c.lookup); errors.As(err, &rangeErr) {
+		rangeErr.Position = pos
+		return rangeErr
+	}
+	names := func(alias string) []string {
+		units, _ := c.registry.Lookup(alias)
+		names := make([]string, len(units))
+		for i, unit := range units {
+			names[i] = unit.Name
+		}
+		return names
+	}
+	// Generated prefixed variants are suggested by their spelled-out names
+	// only, after the units people write by name: "aev" and "dal" are never
+	// what a typo meant
+	var named, prefixed []string
+	for _, alias := range c.registry.Aliases() {
+		switch c.generated(alias) {
+		case "":
+			named = append(named, alias)
+		case "name":
+			prefixed = append(prefixed, alias)
 		}
 	}
+	suggestions := suggest(unknownUnit, named, names)
+	for _, alias := range suggest(unknownUnit, prefixed, names) {
+		if len(suggestions) == 3 {
+			break
+		}
+		suggestions = append(suggestions, alias)
+	}
+	return &UnknownUnitError{Unit: unknownUnit, Position: pos, Suggestions: suggestions}
+}
 
-	return Unit{}, false
+// generated reports how an alias names generated prefixed variants: "" when
+// it names any other unit, "name" when it spells out the prefix, as in
+// "kilometers", and "symbol" when it starts with the prefix symbol, as in
+// "aev".
+func (c *Converter) generated(alias string) string {
+	units, _ := c.registry.Lookup(alias)
+	spelled := true
+	for _, unit := range units {
+		if unit.prefix == "" {
+			return ""
+		}
+		spelled = spelled && strings.HasPrefix(alias, unit.prefix)
+	}
+	if spelled {
+		return "name"
+	}
+	return "symbol"
 }
 
-func (c *Converter) createNotFoundError(unknownUnit string) error {
-	const maxSuggestionDistance = 2
-	bestMatch := ""
-	minDist := maxSuggestionDistance + 1
+// suggest returns up to three of the sorted aliases that are within two
+// edits of word, closest first. Ties go to the alphabetically first alias,
+// and each thing, as named by names, is suggested once, by its closest alias.
+func suggest(word string, aliases []string, names func(alias string) []string) []string {
+	const (
+		maxSuggestionDistance = 2
+		maxSuggestions        = 3
+	)
+	type suggestion struct {
+		alias string
+		dist  int
+	}
 
-	for alias := range c.unitMap {
-		if len(alias) < 3 {
+	folded := strings.ToLower(word)
+	var ranked []suggestion
+	for _, alias := range aliases {
+		if utf8.RuneCountInString(alias) < 3 {
 			continue
 		}
-		dist := levenshtein(unknownUnit, alias)
-		if dist < minDist {
-			minDist = dist
-			bestMatch = alias
+		if dist := levenshtein(folded, alias); dist <= maxSuggestionDistance {
+			ranked = append(ranked, suggestion{alias, dist})
 		}
 	}
-
-	if minDist <= maxSuggestionDistance {
-		return fmt.Errorf("unknown unit: '%s'. Did you mean '%s'?", unknownUnit, bestMatch)
+	// Aliases are sorted, so a stable sort keeps ties alphabetical
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].dist < ranked[j].dist })
+
+	var suggestions []string
+	suggested := make(map[string]bool)
+	for _, s := range ranked {
+		named := names(s.alias)
+		if suggested[named[0]] {
+			continue
+		}
+		for _, name := range named {
+			suggested[name] = true
+		}
+		suggestions = append(suggestions, s.alias)
+		if len(suggestions) == maxSuggestions {
+			break
+		}
 	}
-	return fmt.Errorf("unknown unit: '%s'", unknownUnit)
+	return suggestions
 }
 
 // levenshtein calculates the Levenshtein distance between two strings.
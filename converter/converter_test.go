@@ -0,0 +1,55 @@
+package converter
+
+import (
+	"math"
+	"testing"
+)
+
+// resultTest is an expression and the result Process should give for it.
+// exact is the expected Result.Exact, or "" when there should be none.
+type resultTest struct {
+	input  string
+	want   float64
+	symbol string
+	exact  string
+}
+
+func checkResults(t *testing.T, c *Converter, tests []resultTest) {
+	t.Helper()
+	for _, tt := range tests {
+		result, err := c.Process(tt.input)
+		if err != nil {
+			t.Errorf("Process(%q) error = %v", tt.input, err)
+			continue
+		}
+		if math.Abs(result.Value-tt.want) > 1e-9*math.Abs(tt.want) || result.UnitSymbol != tt.symbol {
+			t.Errorf("Process(%q) = %v %s, want %v %s", tt.input, result.Value, result.UnitSymbol, tt.want, tt.symbol)
+		}
+		switch {
+		case tt.exact == "" && result.Exact != nil:
+			t.Errorf("Process(%q) exact = %s, want none", tt.input, result.Exact.RatString())
+		case tt.exact != "" && (result.Exact == nil || result.Exact.RatString() != tt.exact):
+			t.Errorf("Process(%q) exact = %v, want %s", tt.input, result.Exact, tt.exact)
+		}
+	}
+}
+
+func TestExactMode(t *testing.T) {
+	c := NewConverter(MustRegisterSystems())
+	c.SetExact(true)
+	checkResults(t, c, []resultTest{
+		{"100 F in C", 37.77777777777778, "°C", "340/9"},
+		{"1/3 cup in tbsp", 5.333333333333333, "tbsp", "16/3"},
+		{"1000 survey feet in m", 304.8006096012192, "m", "1200000/3937"},
+		{"60 rpm in Hz", 1, "Hz", "1"},
+		// Units defined through π have no exact value
+		{"1 rad in deg", 57.29577951308232, "°", ""},
+		{"3000 rpm in rad/s", 314.1592653589793, "rad/s", ""},
+		{"1 parsec in ly", 3.2615637771674337, "ly", ""},
+	})
+
+	c.SetExact(false)
+	checkResults(t, c, []resultTest{
+		{"100 F in C", 37.77777777777778, "°C", ""},
+	})
+}
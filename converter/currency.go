@@ -0,0 +1,251 @@
+package converter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A rates file is a snapshot of exchange rates, read from disk so that
+// currencies convert offline. It is JSON:
+//
+//	{"base": "USD", "date": "2026-10-01", "rates": {"EUR": 0.92, "INR": "83.12"}}
+//
+// or CSV with a header row:
+//
+//	base,currency,rate,date
+//	USD,EUR,0.92,2026-10-01
+//	USD,INR,83.12,2026-10-01
+//
+// Each rate is how much of a currency one unit of the base buys. Rates may be
+// numbers or strings holding an exact decimal or fraction.
+
+// currencySystem is the name of the system the rates register.
+const currencySystem = "Currency"
+
+// Rates is a snapshot of exchange rates: one unit of Base buys Rates[code] of
+// each other currency, as of AsOf.
+type Rates struct {
+	Base  string
+	AsOf  time.Time
+	Rates map[string]*big.Rat
+}
+
+// currencyNames names the currencies a snapshot is likely to quote, and the
+// symbols and words they are written with. "$" is the US dollar.
+var currencyNames = map[string]struct {
+	name    string
+	aliases []string
+}{
+	"USD": {"US dollars", []string{"$", "dollar", "dollars", "us dollar", "buck", "bucks"}},
+	"EUR": {"Euros", []string{"€", "euro", "euros"}},
+	"GBP": {"Pounds sterling", []string{"£", "sterling", "pound sterling"}},
+	"JPY": {"Japanese yen", []string{"¥", "yen"}},
+	"CNY": {"Chinese yuan", []string{"yuan", "renminbi", "rmb"}},
+	"INR": {"Indian rupees", []string{"₹", "rupee", "rupees"}},
+	"KRW": {"South Korean won", []string{"₩", "won"}},
+	"RUB": {"Russian rubles", []string{"₽", "ruble", "rubles", "rouble", "roubles"}},
+	"TRY": {"Turkish lira", []string{"₺", "lira"}},
+	"ILS": {"Israeli new shekels", []string{"₪", "shekel", "shekels"}},
+	"VND": {"Vietnamese dong", []string{"₫", "dong"}},
+	"PHP": {"Philippine pesos", []string{"₱"}},
+	"UAH": {"Ukrainian hryvnias", []string{"₴", "hryvnia", "hryvnias"}},
+	"NGN": {"Nigerian naira", []string{"₦", "naira"}},
+	"THB": {"Thai baht", []string{"฿", "baht"}},
+	"CAD": {"Canadian dollars", []string{"canadian dollar"}},
+	"AUD": {"Australian dollars", []string{"australian dollar"}},
+	"NZD": {"New Zealand dollars", []string{"new zealand dollar"}},
+	"HKD": {"Hong Kong dollars", []string{"hong kong dollar"}},
+	"SGD": {"Singapore dollars", []string{"singapore dollar"}},
+	"CHF": {"Swiss francs", []string{"swiss franc"}},
+	"MXN": {"Mexican pesos", []string{"mexican peso"}},
+	"BRL": {"Brazilian reais", []string{"reais"}},
+	"ZAR": {"South African rand", []string{"rand"}},
+	"SEK": {"Swedish kronor", []string{"swedish krona"}},
+	"NOK": {"Norwegian kroner", []string{"norwegian krone"}},
+	"DKK": {"Danish kroner", []string{"danish krone"}},
+	"PLN": {"Polish zloty", []string{"zloty", "złoty"}},
+}
+
+// ParseRatesJSON reads a JSON rates file. Fields other than base, date and
+// rates are ignored.
+func ParseRatesJSON(rd io.Reader) (*Rates, error) {
+	var snapshot struct {
+		Base  string                   `json:"base"`
+		Date  string                   `json:"date"`
+		Rates map[string]*definedRatio `json:"rates"`
+	}
+	if err := json.NewDecoder(rd).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid exchange rates: %w", err)
+	}
+	asOf, err := parseRatesDate(snapshot.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := &Rates{Base: snapshot.Base, AsOf: asOf, Rates: make(map[string]*big.Rat)}
+	for code, rate := range snapshot.Rates {
+		if rate == nil {
+			return nil, fmt.Errorf("currency '%s': missing rate", code)
+		}
+		rates.Rates[code] = rate.Rat
+	}
+	return rates, nil
+}
+
+// ParseRatesCSV reads a CSV rates file. Its header names the base, currency,
+// rate and date columns, in any order. Every row must quote the same base,
+// and the snapshot is as old as its oldest rate.
+func ParseRatesCSV(rd io.Reader) (*Rates, error) {
+	records, err := csv.NewReader(rd).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid exchange rates: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("invalid exchange rates: missing header")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"base", "currency", "rate", "date"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("invalid exchange rates: missing '%s' column", name)
+		}
+	}
+
+	rates := &Rates{Rates: make(map[string]*big.Rat)}
+	for line, record := range records[1:] {
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+		base, code := field("base"), field("currency")
+		if rates.Base == "" {
+			rates.Base = base
+		} else if !strings.EqualFold(base, rates.Base) {
+			return nil, fmt.Errorf("line %d: base %s differs from %s", line+2, base, rates.Base)
+		}
+		rate, ok := new(big.Rat).SetString(field("rate"))
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid number: '%s'", line+2, field("rate"))
+		}
+		asOf, err := parseRatesDate(field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		if rates.AsOf.IsZero() || asOf.Before(rates.AsOf) {
+			rates.AsOf = asOf
+		}
+		rates.Rates[code] = rate
+	}
+	return rates, nil
+}
+
+func parseRatesDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("invalid exchange rates: missing date")
+	}
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: '%s'", s)
+}
+
+// LoadRatesFile registers the currencies of a rates file, replacing any
+// rates loaded before. Files ending in .csv are read as CSV, others as JSON.
+func (r *Registry) LoadRatesFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	parse := ParseRatesJSON
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		parse = ParseRatesCSV
+	}
+	rates, err := parse(f)
+	if err == nil {
+		err = r.RegisterRates(rates)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// RegisterRates registers a unit for every currency of the snapshot, named
+// by its ISO 4217 code, replacing the currencies of any earlier snapshot.
+// Currencies defined in a definitions file stay registered. Nothing changes
+// unless every rate is positive.
+func (r *Registry) RegisterRates(rates *Rates) error {
+	system, err := rates.system()
+	if err != nil {
+		return err
+	}
+	r.unregister(currencySystem, func(unit Unit) bool { return unit.rate })
+	r.Register(system)
+	r.system(currencySystem).BaseUnit = system.BaseUnit
+	r.ratesAsOf = rates.AsOf
+	return nil
+}
+
+// RatesAsOf returns the date of the registered exchange rates, or the zero
+// time when none are loaded.
+func (r *Registry) RatesAsOf() time.Time {
+	return r.ratesAsOf
+}
+
+func (rates *Rates) system() (UnitSystem, error) {
+	base, err := currencyCode(rates.Base)
+	if err != nil {
+		return UnitSystem{}, err
+	}
+	factors := map[string]*big.Rat{base: big.NewRat(1, 1)}
+	for code, rate := range rates.Rates {
+		code, err := currencyCode(code)
+		if err != nil {
+			return UnitSystem{}, err
+		}
+		if rate == nil || rate.Sign() <= 0 {
+			return UnitSystem{}, fmt.Errorf("currency '%s': rate must be a positive number", code)
+		}
+		if code == base && !isOne(rate) {
+			return UnitSystem{}, fmt.Errorf("currency '%s': the base must have a rate of 1", code)
+		}
+		// One unit of the currency is worth 1/rate of the base
+		factors[code] = new(big.Rat).Inv(rate)
+	}
+
+	system := UnitSystem{Name: currencySystem, Dimension: DimCurrency, Units: make(map[string]Unit)}
+	for code, factor := range factors {
+		unit := Unit{Name: code, Symbol: code, Factor: factor, rate: true}
+		if known, ok := currencyNames[code]; ok {
+			unit.Name, unit.Aliases = known.name, known.aliases
+		}
+		system.Units[unit.Name] = unit
+		if code == base {
+			system.BaseUnit = unit.Name
+		}
+	}
+	return system, nil
+}
+
+// currencyCode validates an ISO 4217 code, such as "EUR", and returns it in
+// upper case.
+func currencyCode(code string) (string, error) {
+	upper := strings.ToUpper(strings.TrimSpace(code))
+	if len(upper) != 3 || strings.Trim(upper, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("invalid currency code: '%s'", code)
+	}
+	return upper, nil
+}
@@ -0,0 +1,135 @@
+package converter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRates = `{"base": "USD", "date": "2026-10-01", "rates": {"EUR": "0.92", "GBP": "0.79", "JPY": 149.5, "INR": "83.12"}}`
+
+func TestRates(t *testing.T) {
+	rates, err := ParseRatesJSON(strings.NewReader(testRates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := MustRegisterSystems()
+	if err := registry.RegisterRates(rates); err != nil {
+		t.Fatal(err)
+	}
+	c := NewConverter(registry)
+	checkResults(t, c, []resultTest{
+		{"100 USD in EUR", 92, "EUR", ""},
+		{"€50 in £", 42.93478260869565, "GBP", ""},
+		{"$20 + €10 in USD", 30.869565217391305, "USD", ""},
+		{"1000 yen in rupees", 555.9866220735786, "INR", ""},
+	})
+
+	result, err := c.Process("100 USD in EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); !result.RatesAsOf.Equal(want) {
+		t.Errorf("RatesAsOf = %v, want %v", result.RatesAsOf, want)
+	}
+
+	_, err = c.Process("5 USD in kg")
+	var incompatible *IncompatibleUnitsError
+	if !errors.As(err, &incompatible) {
+		t.Errorf("Process(%q) error = %v, want *IncompatibleUnitsError", "5 USD in kg", err)
+	}
+}
+
+func TestRatesCSV(t *testing.T) {
+	rates, err := ParseRatesCSV(strings.NewReader("base,currency,rate,date\nUSD,EUR,0.92,2026-10-01\nUSD,GBP,0.79,2026-09-30\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := MustRegisterSystems()
+	if err := registry.RegisterRates(rates); err != nil {
+		t.Fatal(err)
+	}
+	c := NewConverter(registry)
+	c.SetExact(true)
+	checkResults(t, c, []resultTest{
+		{"100 GBP in EUR", 116.45569620253164, "EUR", "9200/79"},
+	})
+	// The snapshot is as old as its oldest rate
+	if want := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC); !registry.RatesAsOf().Equal(want) {
+		t.Errorf("RatesAsOf = %v, want %v", registry.RatesAsOf(), want)
+	}
+}
+
+func TestInvalidRates(t *testing.T) {
+	tests := []struct {
+		rates string
+		want  string
+	}{
+		{`{"base": "USD", "date": "2026-10-01", "rates": {"EUR": "-1"}}`, "currency 'EUR': rate must be a positive number"},
+		{`{"base": "USD", "date": "2026-10-01", "rates": {"EURO": "1"}}`, "invalid currency code: 'EURO'"},
+		{`{"base": "USD", "date": "2026-10-01", "rates": {"USD": "2"}}`, "currency 'USD': the base must have a rate of 1"},
+		{`{"base": "USD", "rates": {"EUR": "1"}}`, "invalid exchange rates: missing date"},
+	}
+	for _, tt := range tests {
+		rates, err := ParseRatesJSON(strings.NewReader(tt.rates))
+		if err == nil {
+			err = MustRegisterSystems().RegisterRates(rates)
+		}
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("rates %s: error = %v, want %s", tt.rates, err, tt.want)
+		}
+	}
+}
+
+func TestReloadRatesKeepsDefinedCurrencies(t *testing.T) {
+	register := func(registry *Registry, snapshot string) {
+		t.Helper()
+		rates, err := ParseRatesJSON(strings.NewReader(snapshot))
+		if err == nil {
+			err = registry.RegisterRates(rates)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := MustRegisterSystems()
+	register(registry, testRates)
+	definitions := `{"units": [{"name": "Cents", "symbol": "¢", "aliases": ["cent", "cents"], "dimension": "currency", "factor": "0.01"}]}`
+	if err := registry.LoadDefinitions(strings.NewReader(definitions)); err != nil {
+		t.Fatal(err)
+	}
+	register(registry, `{"base": "USD", "date": "2026-10-08", "rates": {"EUR": "0.9"}}`)
+
+	c := NewConverter(registry)
+	checkResults(t, c, []resultTest{
+		{"250 cents in EUR", 2.25, "EUR", ""},
+		{"1 USD in cents", 100, "¢", ""},
+	})
+	// Currencies of the earlier snapshot are gone
+	var unknown *UnknownUnitError
+	if _, err := c.Process("100 GBP in USD"); !errors.As(err, &unknown) {
+		t.Errorf("Process(%q) error = %v, want *UnknownUnitError", "100 GBP in USD", err)
+	}
+	if system, ok := registry.System(currencySystem); !ok || system.BaseUnit != "US dollars" {
+		t.Errorf("currency system = %+v, want base US dollars", system)
+	}
+}
+
+func TestCurrencySymbolBeforeAmount(t *testing.T) {
+	rates, err := ParseRatesJSON(strings.NewReader(testRates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := MustRegisterSystems()
+	if err := registry.RegisterRates(rates); err != nil {
+		t.Fatal(err)
+	}
+	checkResults(t, NewConverter(registry), []resultTest{
+		{"$3.50", 3.5, "USD", ""},
+		{"$1 1/2 in EUR", 1.38, "EUR", ""},
+		{"$1 1/2 + $1", 2.5, "USD", ""},
+		{"₹1000 in EUR", 11.068334937439846, "EUR", ""},
+	})
+}
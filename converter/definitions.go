@@ -0,0 +1,243 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// A definitions file adds units without recompiling:
+//
+//	{
+//	  "units": [
+//	    {"name": "Pallets", "symbol": "plt", "aliases": ["pallet", "pallets"],
+//	     "dimension": "volume", "factor": "1.728"}
+//	  ]
+//	}
+//
+// Factor and offset are relative to the coherent SI unit of the dimension
+// (m³ for volume, K for temperature) and may be numbers or strings holding
+// an exact decimal or fraction ("5/9"). A unit joins the registered system
+// of its dimension and kind ("torque" and "energy" share a dimension) unless
+// it names a system of its own. Absolute temperature scales set "affine" and
+// name the unit of their differences in "delta".
+//
+// The file may also add ingredients, with their density in g/mL, for
+// conversions such as "1 cup of rye flour in g":
+//
+//	"ingredients": [
+//	  {"name": "rye flour", "aliases": ["dark rye"], "density": "0.43"}
+//	]
+
+// UnitDefinition is one unit of a definitions file.
+type UnitDefinition struct {
+	Name       string        `json:"name"`
+	Symbol     string        `json:"symbol"`
+	Aliases    []string      `json:"aliases"`
+	Dimension  string        `json:"dimension"`
+	Kind       string        `json:"kind,omitempty"`
+	Factor     *definedRatio `json:"factor"`
+	Offset     *definedRatio `json:"offset,omitempty"`
+	System     string        `json:"system,omitempty"`
+	Prefixable bool          `json:"prefixable,omitempty"`
+	Affine     bool          `json:"affine,omitempty"`
+	Delta      string        `json:"delta,omitempty"`
+}
+
+// IngredientDefinition is one ingredient of a definitions file.
+type IngredientDefinition struct {
+	Name    string        `json:"name"`
+	Aliases []string      `json:"aliases"`
+	Density *definedRatio `json:"density"`
+}
+
+type unitDefinitions struct {
+	Units       []UnitDefinition       `json:"units"`
+	Ingredients []IngredientDefinition `json:"ingredients"`
+}
+
+// definedRatio reads a JSON number or string as an exact ratio.
+type definedRatio struct {
+	*big.Rat
+}
+
+func (d *definedRatio) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(text))
+	if !ok {
+		return fmt.Errorf("invalid number: '%s'", text)
+	}
+	d.Rat = r
+	return nil
+}
+
+// LoadDefinitionsFile registers the units of a definitions file.
+func (r *Registry) LoadDefinitionsFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := r.LoadDefinitions(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadDefinitions registers the units and ingredients of a definitions file
+// read from rd. Nothing is registered unless every unit is valid and none of
+// them clashes with a registered unit: a unit may not reuse the name of a
+// unit in its system, nor share an alias with a unit of the same dimension.
+// Ingredients may not reuse the name or alias of another ingredient.
+func (r *Registry) LoadDefinitions(rd io.Reader) error {
+	decoder := json.NewDecoder(rd)
+	decoder.DisallowUnknownFields()
+	var defs unitDefinitions
+	if err := decoder.Decode(&defs); err != nil {
+		return fmt.Errorf("invalid unit definitions: %w", err)
+	}
+
+	var systems []UnitSystem
+	for _, def := range defs.Units {
+		unit, dim, err := def.unit()
+		if err != nil {
+			return err
+		}
+
+		systemName := def.System
+		if systemName == "" {
+			systemName = r.systemName(dim, def.Kind)
+		}
+		if registered, ok := r.System(systemName); ok && (registered.Dimension != dim || registered.Kind != def.Kind) {
+			return fmt.Errorf("unit '%s': system %s measures %s, not %s", def.Name, registered.Name, describe(registered.Dimension, registered.Kind), describe(dim, def.Kind))
+		}
+
+		system := findSystem(systems, systemName)
+		if system == nil {
+			systems = append(systems, UnitSystem{Name: systemName, Dimension: dim, Kind: def.Kind, Units: make(map[string]Unit)})
+			system = &systems[len(systems)-1]
+		} else if system.Dimension != dim || system.Kind != def.Kind {
+			return fmt.Errorf("unit '%s': system %s measures %s, not %s", def.Name, system.Name, describe(system.Dimension, system.Kind), describe(dim, def.Kind))
+		}
+		if _, exists := system.Units[unit.Name]; exists {
+			return fmt.Errorf("unit '%s' is defined twice", unit.Name)
+		}
+		system.Units[unit.Name] = unit
+		if isOne(unit.Factor) && unit.Offset == nil {
+			system.BaseUnit = unit.Name
+		}
+	}
+
+	if err := r.checkCollisions(systems); err != nil {
+		return err
+	}
+
+	added := NewRegistry()
+	var ingredients []Ingredient
+	for _, def := range defs.Ingredients {
+		ingredient := Ingredient{Name: def.Name, Aliases: def.Aliases}
+		if def.Density != nil {
+			ingredient.Density = def.Density.Rat
+		}
+		if err := r.checkIngredient(ingredient); err != nil {
+			return err
+		}
+		if err := added.checkIngredient(ingredient); err != nil {
+			return fmt.Errorf("ingredient '%s' is defined twice", ingredient.Name)
+		}
+		added.RegisterIngredients(ingredient)
+		ingredients = append(ingredients, ingredient)
+	}
+
+	r.Register(systems...)
+	r.RegisterIngredients(ingredients...)
+	return nil
+}
+
+func (def UnitDefinition) unit() (Unit, Dimension, error) {
+	if strings.TrimSpace(def.Name) == "" {
+		return Unit{}, Dimension{}, fmt.Errorf("unit without a name")
+	}
+	if strings.TrimSpace(def.Symbol) == "" {
+		return Unit{}, Dimension{}, fmt.Errorf("unit '%s': missing symbol", def.Name)
+	}
+	if def.Dimension == "" {
+		return Unit{}, Dimension{}, fmt.Errorf("unit '%s': missing dimension", def.Name)
+	}
+	dim, err := ParseDimension(def.Dimension)
+	if err != nil {
+		return Unit{}, Dimension{}, fmt.Errorf("unit '%s': %w", def.Name, err)
+	}
+	if def.Factor == nil || def.Factor.Sign() <= 0 {
+		return Unit{}, Dimension{}, fmt.Errorf("unit '%s': factor must be a positive number", def.Name)
+	}
+
+	unit := Unit{
+		Name:       def.Name,
+		Symbol:     def.Symbol,
+		Aliases:    def.Aliases,
+		Factor:     def.Factor.Rat,
+		Prefixable: def.Prefixable,
+		Affine:     def.Affine,
+		Delta:      def.Delta,
+	}
+	if def.Offset != nil {
+		unit.Offset = def.Offset.Rat
+	}
+	return unit, dim, nil
+}
+
+// systemName is the name of the registered system of a dimension and kind,
+// or the name of the kind or dimension itself when there is none.
+func (r *Registry) systemName(dim Dimension, kind string) string {
+	for _, system := range r.systems {
+		if system.Dimension == dim && system.Kind == kind {
+			return system.Name
+		}
+	}
+	name := describe(dim, kind)
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func findSystem(systems []UnitSystem, name string) *UnitSystem {
+	for i := range systems {
+		if strings.EqualFold(systems[i].Name, name) {
+			return &systems[i]
+		}
+	}
+	return nil
+}
+
+// checkCollisions reports the first unit of systems, prefixed variants
+// included, that could not be told apart from another unit once registered.
+func (r *Registry) checkCollisions(systems []UnitSystem) error {
+	added, collisions := RegisterSystems(systems...)
+	if err := checkDistinct(collisions); err != nil {
+		return err
+	}
+
+	for _, unit := range added.Units() {
+		if registered := r.system(unit.System); registered != nil {
+			if _, exists := registered.Units[unit.Name]; exists {
+				return fmt.Errorf("unit '%s' is already defined in %s", unit.Name, registered.Name)
+			}
+		}
+		for _, key := range unit.keys() {
+			for _, other := range r.units[strings.ToLower(key)] {
+				if other.Dimension == unit.Dimension && other.hasKey(key) {
+					return fmt.Errorf("alias '%s' of %s is already used by %s", key, unit.Name, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDefinitions = `{"units": [
+	{"name": "Pallets", "symbol": "plt", "aliases": ["pallet", "pallets"], "dimension": "volume", "factor": "1.728"},
+	{"name": "Totes", "symbol": "tote", "aliases": ["totes"], "dimension": "volume", "factor": 1.04},
+	{"name": "Rankine", "symbol": "°R", "aliases": ["rankine"], "dimension": "temperature",
+	 "factor": "5/9", "affine": true, "delta": "Delta Fahrenheit"}
+]}`
+
+func TestLoadDefinitions(t *testing.T) {
+	registry := MustRegisterSystems()
+	if err := registry.LoadDefinitions(strings.NewReader(testDefinitions)); err != nil {
+		t.Fatal(err)
+	}
+	c := NewConverter(registry)
+	c.SetExact(true)
+	checkResults(t, c, []resultTest{
+		{"2 pallets in totes", 3.3230769230769233, "tote", "216/65"},
+		{"1 pallet + 1 tote in L", 2768, "L", "2768"},
+		{"500 rankine in F", 40.33, "°F", "4033/100"},
+	})
+}
+
+func TestRejectedDefinitions(t *testing.T) {
+	tests := []struct {
+		definitions string
+		want        string
+	}{
+		{
+			`{"units": [{"name": "Odds", "symbol": "odd", "dimension": "length^200", "factor": "1"}]}`,
+			"unit 'Odds': exponent out of range in dimension 'length^200': powers run from -127 to 127",
+		},
+		{
+			`{"units": [{"name": "Jugs", "symbol": "jug", "dimension": "volume", "factor": "-2"}]}`,
+			"unit 'Jugs': factor must be a positive number",
+		},
+		{
+			`{"units": [{"name": "Jugs", "symbol": "jug", "aliases": ["pint"], "dimension": "volume", "factor": "0.002"}]}`,
+			"alias 'pint' of Jugs is already used by Imperial Pint",
+		},
+		{
+			`{"units": [{"name": "Jugs", "dimension": "volume", "factor": "2"}]}`,
+			"unit 'Jugs': missing symbol",
+		},
+	}
+	for _, tt := range tests {
+		registry := MustRegisterSystems()
+		err := registry.LoadDefinitions(strings.NewReader(tt.definitions))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("LoadDefinitions(%s) error = %v, want %s", tt.definitions, err, tt.want)
+		}
+		// A rejected file leaves the registry as it was
+		if _, ok := registry.Lookup("jug"); ok {
+			t.Errorf("LoadDefinitions(%s) registered 'jug'", tt.definitions)
+		}
+	}
+}
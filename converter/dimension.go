@@ -0,0 +1,217 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	dimLength = iota
+	dimMass
+	dimTime
+	dimTemperature
+	dimInformation
+	dimCurrent
+	dimCurrency
+	numBaseDimensions
+)
+
+var baseDimensionNames = [numBaseDimensions]string{"length", "mass", "time", "temperature", "information", "current", "currency"}
+
+// Dimension holds the exponent of each base quantity, so m/s² is
+// {length: 1, time: -2}. Two units can only be added or converted into
+// one another when their dimensions are equal.
+type Dimension [numBaseDimensions]int8
+
+// maxExponent is the largest exponent, up or down, a Dimension holds.
+const maxExponent = math.MaxInt8
+
+var (
+	Dimensionless  = Dimension{}
+	DimLength      = Dimension{dimLength: 1}
+	DimMass        = Dimension{dimMass: 1}
+	DimTime        = Dimension{dimTime: 1}
+	DimTemperature = Dimension{dimTemperature: 1}
+	DimArea        = Dimension{dimLength: 2}
+	DimVolume      = Dimension{dimLength: 3}
+	DimSpeed       = Dimension{dimLength: 1, dimTime: -1}
+	DimEnergy      = Dimension{dimLength: 2, dimMass: 1, dimTime: -2}
+	DimPower       = Dimension{dimLength: 2, dimMass: 1, dimTime: -3}
+	DimPressure    = Dimension{dimLength: -1, dimMass: 1, dimTime: -2}
+	DimForce       = Dimension{dimLength: 1, dimMass: 1, dimTime: -2}
+	DimInformation = Dimension{dimInformation: 1}
+	DimDataRate    = Dimension{dimInformation: 1, dimTime: -1}
+	DimFrequency   = Dimension{dimTime: -1}
+	DimDensity     = Dimension{dimLength: -3, dimMass: 1}
+	DimCurrent     = Dimension{dimCurrent: 1}
+	DimCharge      = Dimension{dimCurrent: 1, dimTime: 1}
+	DimVoltage     = Dimension{dimLength: 2, dimMass: 1, dimTime: -3, dimCurrent: -1}
+	DimResistance  = Dimension{dimLength: 2, dimMass: 1, dimTime: -3, dimCurrent: -2}
+	DimCapacitance = Dimension{dimLength: -2, dimMass: -1, dimTime: 4, dimCurrent: 2}
+	DimConductance = Dimension{dimLength: -2, dimMass: -1, dimTime: 3, dimCurrent: 2}
+	DimCurrency    = Dimension{dimCurrency: 1}
+	// DimFuelEconomy is distance per volume of fuel.
+	DimFuelEconomy = Dimension{dimLength: -2}
+)
+
+var dimensionNames = map[Dimension]string{
+	Dimensionless:  "dimensionless",
+	DimLength:      "length",
+	DimMass:        "mass",
+	DimTime:        "time",
+	DimTemperature: "temperature",
+	DimArea:        "area",
+	DimVolume:      "volume",
+	DimSpeed:       "speed",
+	DimEnergy:      "energy",
+	DimPower:       "power",
+	DimPressure:    "pressure",
+	DimForce:       "force",
+	DimInformation: "information",
+	DimDataRate:    "data rate",
+	DimFrequency:   "frequency",
+	DimFuelEconomy: "fuel economy",
+	DimDensity:     "density",
+	DimCurrent:     "current",
+	DimCharge:      "charge",
+	DimVoltage:     "voltage",
+	DimResistance:  "resistance",
+	DimCapacitance: "capacitance",
+	DimConductance: "conductance",
+	DimCurrency:    "currency",
+}
+
+func (d Dimension) Mul(o Dimension) Dimension {
+	for i := range d {
+		d[i] += o[i]
+	}
+	return d
+}
+
+func (d Dimension) Div(o Dimension) Dimension {
+	for i := range d {
+		d[i] -= o[i]
+	}
+	return d
+}
+
+// Pow raises the dimension to the power n. It fails rather than wrap around
+// when an exponent would exceed maxExponent.
+func (d Dimension) Pow(n int) (Dimension, error) {
+	for i := range d {
+		exp := int(d[i]) * n
+		if exp > maxExponent || exp < -maxExponent {
+			return Dimension{}, fmt.Errorf("exponent out of range: (%s)^%d", d, n)
+		}
+		d[i] = int8(exp)
+	}
+	return d, nil
+}
+
+// product multiplies the dimension by o, or divides it by o, failing where
+// Mul and Div would wrap around.
+func (d Dimension) product(o Dimension, divide bool) (Dimension, error) {
+	for i := range d {
+		exp := int(d[i]) + int(o[i])
+		if divide {
+			exp = int(d[i]) - int(o[i])
+		}
+		if exp > maxExponent || exp < -maxExponent {
+			return Dimension{}, fmt.Errorf("exponent out of range: %s^%d", baseDimensionNames[i], exp)
+		}
+		d[i] = int8(exp)
+	}
+	return d, nil
+}
+
+// String returns the common name of the dimension ("speed") or, for
+// dimensions without one, its base quantities ("length^2*mass/time^2").
+func (d Dimension) String() string {
+	if name, ok := dimensionNames[d]; ok {
+		return name
+	}
+
+	var num, den []string
+	for i, exp := range d {
+		switch {
+		case exp == 1:
+			num = append(num, baseDimensionNames[i])
+		case exp > 1:
+			num = append(num, fmt.Sprintf("%s^%d", baseDimensionNames[i], exp))
+		case exp == -1:
+			den = append(den, baseDimensionNames[i])
+		case exp < -1:
+			den = append(den, fmt.Sprintf("%s^%d", baseDimensionNames[i], -exp))
+		}
+	}
+
+	s := strings.Join(num, "*")
+	if s == "" {
+		s = "1"
+	}
+	if len(den) > 0 {
+		s += "/" + strings.Join(den, "/")
+	}
+	return s
+}
+
+// ParseDimension reads a dimension written the way String writes it: a
+// common name ("speed") or a product of base quantities ("length^2*mass/time^2").
+func ParseDimension(s string) (Dimension, error) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), ""))
+	for dim, name := range dimensionNames {
+		if name == s {
+			return dim, nil
+		}
+	}
+
+	var exps [numBaseDimensions]int
+	for i, group := range strings.Split(s, "/") {
+		sign := 1
+		if i > 0 {
+			sign = -1
+		}
+		for _, factor := range strings.Split(group, "*") {
+			if factor == "1" && i == 0 {
+				continue
+			}
+			name, exp := factor, 1
+			if base, power, ok := strings.Cut(factor, "^"); ok {
+				n, err := strconv.Atoi(power)
+				if err != nil {
+					return Dimension{}, fmt.Errorf("invalid exponent in dimension '%s'", s)
+				}
+				name, exp = base, n
+			}
+			index := -1
+			for j, base := range baseDimensionNames {
+				if base == name {
+					index = j
+				}
+			}
+			if index < 0 {
+				return Dimension{}, fmt.Errorf("unknown dimension: '%s'", name)
+			}
+			exps[index] += sign * exp
+		}
+	}
+
+	var d Dimension
+	for i, exp := range exps {
+		if exp > maxExponent || exp < -maxExponent {
+			return Dimension{}, fmt.Errorf("exponent out of range in dimension '%s': powers run from -%d to %d", s, maxExponent, maxExponent)
+		}
+		d[i] = int8(exp)
+	}
+	return d, nil
+}
+
+// describe names what a quantity measures: its kind, or else its dimension.
+func describe(dim Dimension, kind string) string {
+	if kind != "" {
+		return kind
+	}
+	return dim.String()
+}
@@ -0,0 +1,131 @@
+package converter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// displayScale marks screen units whose length depends on the display they
+// are shown on. Their registered Factor is their length at the defaults.
+type displayScale int
+
+const (
+	// scalePixel is one pixel: 1/DPI of an inch.
+	scalePixel displayScale = iota + 1
+	// scaleFont is one font size, a number of pixels.
+	scaleFont
+)
+
+// CSS lays out at 96 px to the inch with 16 px text.
+var (
+	defaultDPI      = big.NewRat(96, 1)
+	defaultFontSize = big.NewRat(16, 1)
+)
+
+// SetDPI sets the pixels per inch that px, em and rem are measured at. The
+// default is 96, the CSS reference pixel.
+func (c *Converter) SetDPI(dpi float64) error {
+	r, err := displaySetting("DPI", dpi)
+	if err != nil {
+		return err
+	}
+	c.dpi = r
+	return nil
+}
+
+// SetFontSize sets the size of em and rem in pixels. The default is 16.
+func (c *Converter) SetFontSize(px float64) error {
+	r, err := displaySetting("font size", px)
+	if err != nil {
+		return err
+	}
+	c.fontSize = r
+	return nil
+}
+
+func displaySetting(name string, value float64) (*big.Rat, error) {
+	r := new(big.Rat)
+	if r.SetFloat64(value) == nil || r.Sign() <= 0 {
+		return nil, fmt.Errorf("%s must be a positive number, not %g", name, value)
+	}
+	return r, nil
+}
+
+// scaled measures a screen unit at the converter's display settings.
+func (c *Converter) scaled(unit Unit) Unit {
+	var pixels *big.Rat
+	switch unit.scale {
+	case scalePixel:
+		pixels = big.NewRat(1, 1)
+	case scaleFont:
+		pixels = c.fontSize
+	default:
+		return unit
+	}
+	// m = px · 0.0254 m/in ÷ px/in
+	unit.Factor = new(big.Rat).Quo(new(big.Rat).Mul(pixels, ratio("0.0254")), c.dpi)
+	unit.ToBaseFunc, unit.FromBaseFunc = nil, nil
+	return unit.withFactorFuncs()
+}
+
+// splitSettings separates an "at" clause that overrides the display settings
+// for one conversion, as in "12pt in px at 144 dpi" or "2 em in pt at 20 px".
+// Settings are joined by "and": "at 144 dpi and 20 px".
+func splitSettings(tokens []token) ([]token, []token) {
+	for i := len(tokens) - 2; i >= 0; i-- {
+		if tokens[i].Kind != tokenUnit || !strings.EqualFold(tokens[i].Text, "at") || tokens[i+1].Kind != tokenNumber {
+			continue
+		}
+		end := i + 1
+		for end < len(tokens) && !isTargetKeyword(tokens[end]) {
+			end++
+		}
+		rest := append(append([]token(nil), tokens[:i]...), tokens[end:]...)
+		return rest, tokens[i+1 : end]
+	}
+	return tokens, nil
+}
+
+func isTargetKeyword(tok token) bool {
+	return tok.Kind == tokenUnit && (strings.EqualFold(tok.Text, "in") || strings.EqualFold(tok.Text, "to"))
+}
+
+// withSettings returns a copy of the converter with the display settings of
+// an "at" clause.
+func (c *Converter) withSettings(settings []token) (*Converter, error) {
+	if len(settings) == 0 {
+		return c, nil
+	}
+	conv := *c
+	for i := 0; i < len(settings); i += 3 {
+		value := settings[i]
+		if value.Kind != tokenNumber || i+1 >= len(settings) || settings[i+1].Kind != tokenUnit {
+			return nil, &SyntaxError{Message: "expected a setting such as '144 dpi' or '20 px'", Position: value.Pos}
+		}
+		var name string
+		switch setting := settings[i+1]; strings.ToLower(setting.Text) {
+		case "dpi", "ppi":
+			name, conv.dpi = "DPI", value.Value
+		case "px", "pixel", "pixels":
+			name, conv.fontSize = "font size", value.Value
+		default:
+			return nil, &SyntaxError{Message: fmt.Sprintf("unknown display setting '%s'; use dpi or px", setting.Text), Position: setting.Pos}
+		}
+		if value.Value.Sign() <= 0 {
+			return nil, &SyntaxError{Message: fmt.Sprintf("%s must be a positive number, not %s", name, value.Text), Position: value.Pos}
+		}
+		if i+2 == len(settings) {
+			break
+		}
+		and := settings[i+2]
+		if and.Kind != tokenOperator || and.Text != "+" {
+			return nil, &SyntaxError{Message: "expected 'and' between display settings", Position: and.Pos}
+		}
+		// "at 20 px and" is missing its second setting
+		if i+3 == len(settings) {
+			return nil, &SyntaxError{Message: "expected a display setting after 'and'", Position: and.Pos}
+		}
+	}
+	return &conv, nil
+}
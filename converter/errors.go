@@ -0,0 +1,180 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IncompatibleUnitsError is returned when an expression adds, subtracts or
+// converts between units that measure different things: different
+// dimensions, or different kinds of the same dimension such as torque and
+// energy.
+type IncompatibleUnitsError struct {
+	From          string
+	FromDimension Dimension
+	FromKind      string
+	To            string
+	ToDimension   Dimension
+	ToKind        string
+}
+
+func (e *IncompatibleUnitsError) Error() string {
+	if e.FromDimension == e.ToDimension {
+		return fmt.Sprintf("incompatible kinds: '%s' (%s) and '%s' (%s)", e.From, e.FromKind, e.To, e.ToKind)
+	}
+	return fmt.Sprintf("incompatible dimensions: '%s' (%s) and '%s' (%s)", e.From, describe(e.FromDimension, e.FromKind), e.To, describe(e.ToDimension, e.ToKind))
+}
+
+// AmbiguousUnitError is returned when a unit alias belongs to several units
+// and nothing else in the expression tells which one was meant.
+type AmbiguousUnitError struct {
+	Unit       string
+	Candidates []Unit
+}
+
+func (e *AmbiguousUnitError) Error() string {
+	options := make([]string, len(e.Candidates))
+	for i, unit := range e.Candidates {
+		options[i] = fmt.Sprintf("%s (%s)", unit.Name, unit.Dimension)
+	}
+	return fmt.Sprintf("ambiguous unit: '%s' could be %s", e.Unit, strings.Join(options, " or "))
+}
+
+// UnknownUnitError is returned for a unit that is not registered and is not
+// a compound of registered units. Position is the byte offset of the unit in
+// the input, and Suggestions lists similarly spelled aliases, closest first.
+type UnknownUnitError struct {
+	Unit        string
+	Position    int
+	Suggestions []string
+}
+
+func (e *UnknownUnitError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown unit: '%s'", e.Unit)
+	}
+	return fmt.Sprintf("unknown unit: '%s'. Did you mean '%s'?", e.Unit, strings.Join(e.Suggestions, "' or '"))
+}
+
+// ExponentRangeError is returned for a unit raised to a power that no
+// dimension holds, such as "m^200": the exponent of each base quantity must
+// lie between -127 and 127.
+type ExponentRangeError struct {
+	Unit     string
+	Position int
+}
+
+func (e *ExponentRangeError) Error() string {
+	return fmt.Sprintf("exponent out of range in '%s': powers run from -%d to %d", e.Unit, maxExponent, maxExponent)
+}
+
+// UnknownIngredientError is returned for the ingredient of a quantity such as
+// "2 cups of sugr" when no ingredient of that name is registered.
+type UnknownIngredientError struct {
+	Ingredient  string
+	Position    int
+	Suggestions []string
+}
+
+func (e *UnknownIngredientError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown ingredient: '%s'", e.Ingredient)
+	}
+	return fmt.Sprintf("unknown ingredient: '%s'. Did you mean '%s'?", e.Ingredient, strings.Join(e.Suggestions, "' or '"))
+}
+
+// UnmeasurableIngredientError is returned for an ingredient measured out in a
+// unit that is neither a volume nor a weight, as in "2 m of sugar".
+type UnmeasurableIngredientError struct {
+	Ingredient string
+	Unit       string
+	Position   int
+}
+
+func (e *UnmeasurableIngredientError) Error() string {
+	return fmt.Sprintf("cannot measure %s in '%s'; use a volume or a weight", e.Ingredient, e.Unit)
+}
+
+// NonLinearUnitError is returned for arithmetic with a unit that is not
+// proportional to its base unit, such as "L/100km", which only converts on
+// its own.
+type NonLinearUnitError struct {
+	Unit     string
+	Position int
+}
+
+func (e *NonLinearUnitError) Error() string {
+	return fmt.Sprintf("cannot calculate with '%s': it does not scale linearly; convert it on its own", e.Unit)
+}
+
+// AbsoluteValueError is returned for arithmetic that has no meaning on an
+// offset scale, such as adding two absolute temperatures or doubling a gauge
+// pressure, and for an absolute temperature expressed as a difference or a
+// difference as an absolute temperature.
+type AbsoluteValueError struct {
+	Message  string
+	Position int
+}
+
+func (e *AbsoluteValueError) Error() string {
+	return e.Message
+}
+
+// DivisionByZeroError is returned for a division by zero, such as "1 m / 0".
+// Position is the byte offset of the "/".
+type DivisionByZeroError struct {
+	Position int
+}
+
+func (e *DivisionByZeroError) Error() string {
+	return "division by zero"
+}
+
+// InfiniteResultError is returned when a unit that is not proportional to its
+// base unit, such as "L/100km", would take an infinite value: "0 L/100km" is
+// an infinite distance per liter.
+type InfiniteResultError struct {
+	// Quantity is what could not be converted, and Unit the unit it was to
+	// be expressed in, if any.
+	Quantity string
+	Unit     string
+	Position int
+}
+
+func (e *InfiniteResultError) Error() string {
+	if e.Unit == "" {
+		return fmt.Sprintf("cannot convert %s: the result would be infinite", e.Quantity)
+	}
+	return fmt.Sprintf("cannot express %s in '%s': the result would be infinite", e.Quantity, e.Unit)
+}
+
+// InvalidNumberError is returned for a malformed number such as "1.2.3" or
+// "1/0".
+type InvalidNumberError struct {
+	Text     string
+	Position int
+}
+
+func (e *InvalidNumberError) Error() string {
+	return fmt.Sprintf("invalid number: '%s'", e.Text)
+}
+
+// EmptyExpressionError is returned when the input holds nothing to convert.
+type EmptyExpressionError struct {
+	Input string
+}
+
+func (e *EmptyExpressionError) Error() string {
+	return fmt.Sprintf("no valid units found in input: '%s'", e.Input)
+}
+
+// SyntaxError is returned when the input cannot be parsed, such as for an
+// unbalanced parenthesis or a stray character.
+type SyntaxError struct {
+	Message  string
+	Position int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
@@ -0,0 +1,548 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dimensionSet is the set of dimensions an expression could have, given the
+// candidates of its ambiguous units.
+type dimensionSet map[Dimension]bool
+
+func (s dimensionSet) intersect(o dimensionSet) dimensionSet {
+	common := make(dimensionSet)
+	for dim := range s {
+		if o[dim] {
+			common[dim] = true
+		}
+	}
+	return common
+}
+
+func unitDimensions(units []Unit) dimensionSet {
+	dims := make(dimensionSet)
+	for _, unit := range units {
+		dims[unit.Dimension] = true
+	}
+	return dims
+}
+
+// resolver picks one unit for every quantity in an expression tree.
+type resolver struct {
+	conv        *Converter
+	candidates  map[*quantityNode][]Unit
+	units       map[*quantityNode]Unit
+	ingredients map[*quantityNode]Ingredient
+}
+
+func (c *Converter) newResolver() *resolver {
+	return &resolver{
+		conv:        c,
+		candidates:  make(map[*quantityNode][]Unit),
+		units:       make(map[*quantityNode]Unit),
+		ingredients: make(map[*quantityNode]Ingredient),
+	}
+}
+
+// lookup finds the candidates of every unit in the tree.
+func (r *resolver) lookup(n node) error {
+	switch n := n.(type) {
+	case *quantityNode:
+		return r.lookupQuantity(n)
+	case *binaryNode:
+		if err := r.lookup(n.left); err != nil {
+			return err
+		}
+		return r.lookup(n.right)
+	case *negateNode:
+		return r.lookup(n.operand)
+	}
+	return nil
+}
+
+// lookupQuantity finds the candidates of the unit of a quantity, and its
+// ingredient. A run of words that is no unit may end in an ingredient, as in
+// "200 g butter".
+func (r *resolver) lookupQuantity(n *quantityNode) error {
+	if n.ingredient != "" {
+		// "ton of refrigeration" is a unit, not a ton of something
+		full := n.unit + " of " + n.ingredient
+		if candidates, ok := r.conv.findUnit(full); ok {
+			n.unit, n.ingredient = full, ""
+			r.candidates[n] = candidates
+			return nil
+		}
+		candidates, ok := r.conv.findUnit(n.unit)
+		if !ok {
+			return r.conv.unknownUnitError(n.unit, n.unitAt)
+		}
+		ingredient, ok := r.conv.registry.Ingredient(n.ingredient)
+		if !ok {
+			return r.conv.unknownIngredientError(n.ingredient, n.ingredientAt)
+		}
+		return r.measure(n, candidates, ingredient)
+	}
+
+	if candidates, ok := r.conv.findUnit(n.unit); ok {
+		r.candidates[n] = candidates
+		return nil
+	}
+	words := strings.Fields(n.unit)
+	for i := 1; i < len(words); i++ {
+		ingredient, ok := r.conv.registry.Ingredient(strings.Join(words[i:], " "))
+		if !ok {
+			continue
+		}
+		if candidates, ok := r.conv.findUnit(strings.Join(words[:i], " ")); ok {
+			n.unit, n.ingredient = strings.Join(words[:i], " "), strings.Join(words[i:], " ")
+			return r.measure(n, candidates, ingredient)
+		}
+	}
+	return r.conv.unknownUnitError(n.unit, n.unitAt)
+}
+
+// measure records the ingredient of a quantity, keeping the candidates of its
+// unit that measure it out by volume or by weight.
+func (r *resolver) measure(n *quantityNode, candidates []Unit, ingredient Ingredient) error {
+	var fits []Unit
+	for _, unit := range candidates {
+		if unit.Dimension == DimVolume || unit.Dimension == DimMass {
+			fits = append(fits, unit)
+		}
+	}
+	if len(fits) == 0 {
+		return &UnmeasurableIngredientError{Ingredient: ingredient.Name, Unit: n.unit, Position: n.unitAt}
+	}
+	r.candidates[n] = fits
+	r.ingredients[n] = ingredient
+	return nil
+}
+
+// dimensions returns every dimension the expression could have.
+func (r *resolver) dimensions(n node) dimensionSet {
+	switch n := n.(type) {
+	case *numberNode:
+		return dimensionSet{Dimensionless: true}
+	case *quantityNode:
+		return unitDimensions(r.candidates[n])
+	case *negateNode:
+		return r.dimensions(n.operand)
+	case *binaryNode:
+		left, right := r.dimensions(n.left), r.dimensions(n.right)
+		if n.op == "+" || n.op == "-" {
+			return left.intersect(right)
+		}
+		dims := make(dimensionSet)
+		for l := range left {
+			for r := range right {
+				if n.op == "*" {
+					dims[l.Mul(r)] = true
+				} else {
+					dims[l.Div(r)] = true
+				}
+			}
+		}
+		return dims
+	}
+	return nil
+}
+
+// constrain narrows the candidates of every unit to those that let the
+// expression take one of the allowed dimensions. A nil set allows anything.
+// Candidates are only narrowed, never emptied; a unit that fits nowhere is
+// reported once the expression is evaluated.
+func (r *resolver) constrain(n node, allowed dimensionSet) {
+	switch n := n.(type) {
+	case *quantityNode:
+		if allowed == nil {
+			return
+		}
+		var fits []Unit
+		for _, unit := range r.candidates[n] {
+			if allowed[unit.Dimension] {
+				fits = append(fits, unit)
+			}
+		}
+		if len(fits) > 0 {
+			r.candidates[n] = fits
+		}
+	case *negateNode:
+		r.constrain(n.operand, allowed)
+	case *binaryNode:
+		left, right := r.dimensions(n.left), r.dimensions(n.right)
+		if n.op == "+" || n.op == "-" {
+			common := left.intersect(right)
+			if allowed != nil {
+				common = common.intersect(allowed)
+			}
+			if len(common) == 0 {
+				return
+			}
+			r.constrain(n.left, common)
+			r.constrain(n.right, common)
+			return
+		}
+		if allowed == nil {
+			r.constrain(n.left, nil)
+			r.constrain(n.right, nil)
+			return
+		}
+		leftFits, rightFits := make(dimensionSet), make(dimensionSet)
+		for l := range left {
+			for rd := range right {
+				combined := l.Mul(rd)
+				if n.op == "/" {
+					combined = l.Div(rd)
+				}
+				if allowed[combined] {
+					leftFits[l], rightFits[rd] = true, true
+				}
+			}
+		}
+		r.constrain(n.left, leftFits)
+		r.constrain(n.right, rightFits)
+	}
+}
+
+// choose settles the units that are still ambiguous after constrain. Like a
+// reader would, it takes the dimension of the units that are not ambiguous,
+// so "oz" is a mass in "10 oz / 2 g", and otherwise the exact spelling and
+// then the better known of the units spelled that way.
+func (r *resolver) choose() error {
+	known := make(dimensionSet)
+	for _, candidates := range r.candidates {
+		if len(candidates) == 1 {
+			known[candidates[0].Dimension] = true
+		}
+	}
+
+	for _, n := range r.order() {
+		candidates := r.candidates[n]
+		if len(candidates) == 1 {
+			r.units[n] = candidates[0]
+			continue
+		}
+		var matches, exact []Unit
+		for _, unit := range candidates {
+			if known[unit.Dimension] {
+				matches = append(matches, unit)
+			}
+			if unit.hasKey(n.unit) {
+				exact = append(exact, unit)
+			}
+		}
+		// Failing that, a unit spelled exactly as written wins: "ps" is a
+		// picosecond, "PS" metric horsepower
+		if len(matches) != 1 {
+			matches = exact
+		}
+		// and of those the better known: "100 F" is Fahrenheit, not farads
+		if len(matches) > 1 {
+			var primary []Unit
+			for _, unit := range matches {
+				if !unit.secondary {
+					primary = append(primary, unit)
+				}
+			}
+			if len(primary) == 1 {
+				matches = primary
+			}
+		}
+		if len(matches) != 1 {
+			return &AmbiguousUnitError{Unit: n.unit, Candidates: candidates}
+		}
+		r.units[n] = matches[0]
+	}
+	return nil
+}
+
+// dated stamps a result that used a currency with the date of the exchange
+// rates.
+func (r *resolver) dated(result *Result, err error) (*Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, unit := range r.units {
+		if unit.Dimension[dimCurrency] != 0 {
+			result.RatesAsOf = r.conv.registry.RatesAsOf()
+			break
+		}
+	}
+	return result, nil
+}
+
+// order lists the quantities left to right, so the first ambiguous unit in
+// the input is the one reported.
+func (r *resolver) order() []*quantityNode {
+	nodes := make([]*quantityNode, 0, len(r.candidates))
+	for n := range r.candidates {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].at < nodes[j].at })
+	return nodes
+}
+
+// quantity is the value of a subexpression in coherent base units.
+type quantity struct {
+	value float64
+	// exact mirrors value until a unit without a factor turns up. It is
+	// only as exact as the factors it was computed from; inexact is set once
+	// one of them is Inexact.
+	exact   *big.Rat
+	inexact bool
+	dim     Dimension
+	// kind tells apart quantities of the same dimension, such as torque and
+	// energy; it is empty for derived quantities.
+	kind string
+	// points counts the absolute temperatures and gauge pressures in a sum:
+	// 1 leaves a point on the scale, 0 a difference, anything else is
+	// meaningless.
+	points int
+	// unit is the unit the result is shown in when there is no target, and
+	// label the unit expression it was built from.
+	unit  *Unit
+	label string
+	// nonlinear marks a value in a unit without a Factor, such as L/100km,
+	// which is not proportional to its base value and so cannot be added,
+	// scaled or combined.
+	nonlinear bool
+	// ingredient is what a volume or mass measures out, if anything.
+	ingredient *Ingredient
+	// at is the position of the subexpression in the input.
+	at int
+}
+
+// evaluate computes the value of the tree with the units chosen by the
+// resolver.
+func (r *resolver) evaluate(n node) (quantity, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		value, _ := n.value.Float64()
+		return quantity{value: value, exact: n.value, dim: Dimensionless, at: n.at}, nil
+
+	case *quantityNode:
+		unit := r.units[n]
+		value, _ := n.value.Float64()
+		q := quantity{
+			value:     unit.ToBaseFunc(value),
+			dim:       unit.Dimension,
+			kind:      unit.Kind,
+			unit:      &unit,
+			label:     unit.Symbol,
+			nonlinear: unit.Factor == nil,
+			inexact:   unit.Inexact,
+			at:        n.at,
+		}
+		// "0 L/100km" is an infinite distance per liter
+		if math.IsInf(q.value, 0) || math.IsNaN(q.value) {
+			return quantity{}, &InfiniteResultError{Quantity: formatNumber(n.value) + " " + unit.Symbol, Position: n.at}
+		}
+		if exact, ok := unit.toBaseExact(n.value); ok {
+			q.exact = exact
+		}
+		if unit.Affine {
+			q.points = 1
+		}
+		if ingredient, ok := r.ingredients[n]; ok {
+			q.ingredient = &ingredient
+		}
+		return q, nil
+
+	case *negateNode:
+		q, err := r.evaluate(n.operand)
+		if err != nil {
+			return quantity{}, err
+		}
+		q.value = -q.value
+		if q.exact != nil {
+			q.exact = new(big.Rat).Neg(q.exact)
+		}
+		q.points = -q.points
+		q.at = n.at
+		return q, nil
+
+	case *binaryNode:
+		left, err := r.evaluate(n.left)
+		if err != nil {
+			return quantity{}, err
+		}
+		right, err := r.evaluate(n.right)
+		if err != nil {
+			return quantity{}, err
+		}
+		for _, q := range []quantity{left, right} {
+			if q.nonlinear {
+				return quantity{}, &NonLinearUnitError{Unit: q.label, Position: q.at}
+			}
+		}
+		if n.op == "+" || n.op == "-" {
+			return add(left, right, n.op == "-")
+		}
+		return multiply(left, right, n.op == "/", n.at)
+	}
+	return quantity{}, fmt.Errorf("unexpected expression")
+}
+
+func add(left, right quantity, subtract bool) (quantity, error) {
+	// "1 cup of flour + 50 g of flour" weighs the cup
+	if left.dim != right.dim && sameIngredient(left, right) {
+		if measured, ok := right.measuredAs(left.dim); ok {
+			right = measured
+		}
+	}
+	if left.dim != right.dim || kindsDiffer(left.kind, right.kind) {
+		return quantity{}, &IncompatibleUnitsError{
+			From:          left.name(),
+			FromDimension: left.dim,
+			FromKind:      left.kind,
+			To:            right.name(),
+			ToDimension:   right.dim,
+			ToKind:        right.kind,
+		}
+	}
+
+	sum := quantity{dim: left.dim, kind: left.kind, unit: right.unit, label: right.label, at: left.at}
+	if sum.kind == "" {
+		sum.kind = right.kind
+	}
+	if right.unit == nil {
+		sum.unit, sum.label = left.unit, left.label
+	}
+	if sameIngredient(left, right) {
+		sum.ingredient = left.ingredient
+	}
+	if left.exact != nil && right.exact != nil {
+		sum.exact = new(big.Rat)
+	}
+	sum.inexact = left.inexact || right.inexact
+	if subtract {
+		sum.value = left.value - right.value
+		if sum.exact != nil {
+			sum.exact.Sub(left.exact, right.exact)
+		}
+		sum.points = left.points - right.points
+	} else {
+		sum.value = left.value + right.value
+		if sum.exact != nil {
+			sum.exact.Add(left.exact, right.exact)
+		}
+		sum.points = left.points + right.points
+	}
+
+	// "20 c + 10 delta f" stays on the scale of its absolute temperature
+	if sum.points == 1 && (sum.unit == nil || !sum.unit.Affine) && left.unit != nil && left.unit.Affine {
+		sum.unit, sum.label = left.unit, left.label
+	}
+	return sum, nil
+}
+
+func multiply(left, right quantity, divide bool, at int) (quantity, error) {
+	for _, q := range []quantity{left, right} {
+		if q.points == 0 {
+			continue
+		}
+		if q.unit != nil && q.unit.Delta == "" {
+			return quantity{}, &AbsoluteValueError{
+				Message:  fmt.Sprintf("cannot multiply or divide a gauge %s; convert '%s' to an absolute unit first", describe(q.dim, q.kind), q.label),
+				Position: q.at,
+			}
+		}
+		return quantity{}, &AbsoluteValueError{
+			Message:  "cannot multiply or divide an absolute temperature; use a difference such as Δ°C",
+			Position: q.at,
+		}
+	}
+
+	product := quantity{inexact: left.inexact || right.inexact, at: left.at}
+	if left.exact != nil && right.exact != nil {
+		product.exact = new(big.Rat)
+	}
+	if divide {
+		if right.value == 0 {
+			return quantity{}, &DivisionByZeroError{Position: at}
+		}
+		product.value = left.value / right.value
+		if product.exact != nil {
+			product.exact.Quo(left.exact, right.exact)
+		}
+	} else {
+		product.value = left.value * right.value
+		if product.exact != nil {
+			product.exact.Mul(left.exact, right.exact)
+		}
+	}
+	dim, err := left.dim.product(right.dim, divide)
+	if err != nil {
+		op := "*"
+		if divide {
+			op = "/"
+		}
+		return quantity{}, &ExponentRangeError{Unit: left.label + op + groupLabel(right.label), Position: at}
+	}
+	product.dim = dim
+
+	// Scaling a quantity keeps its unit: "3 * 2 ft" is in feet
+	switch {
+	case right.label == "":
+		product.unit, product.label, product.kind = left.unit, left.label, left.kind
+		product.ingredient = left.ingredient
+	case left.label == "" && !divide:
+		product.unit, product.label, product.kind = right.unit, right.label, right.kind
+		product.ingredient = right.ingredient
+	case left.label == "":
+		product.label = "1/" + groupLabel(right.label)
+	case divide:
+		product.label = left.label + "/" + groupLabel(right.label)
+	default:
+		product.label = left.label + "*" + groupLabel(right.label)
+	}
+	return product, nil
+}
+
+// name is how an error refers to the quantity: by its unit, or by its value
+// when it is a plain number, as in "1 m + 1".
+func (q quantity) name() string {
+	switch {
+	case q.label != "":
+		return q.label
+	case q.exact != nil:
+		return formatNumber(q.exact)
+	}
+	return strconv.FormatFloat(q.value, 'g', -1, 64)
+}
+
+// formatNumber writes a number for an error message: as a fraction while it
+// is short, and otherwise rounded, so that "1e999999" is not echoed back a
+// million digits long.
+func formatNumber(r *big.Rat) string {
+	const maxDigits = 24
+	if s := r.RatString(); len(s) <= maxDigits {
+		return s
+	}
+	return new(big.Float).SetRat(r).Text('g', 6)
+}
+
+// groupLabel parenthesizes a compound unit on the right of an operator.
+func groupLabel(label string) string {
+	if strings.ContainsAny(label, "*/·") {
+		return "(" + label + ")"
+	}
+	return label
+}
+
+// sameIngredient reports whether two quantities measure out the same
+// ingredient.
+func sameIngredient(a, b quantity) bool {
+	return a.ingredient != nil && b.ingredient != nil && a.ingredient.Name == b.ingredient.Name
+}
+
+// kindsDiffer reports whether two kinds are known and different. A quantity
+// without a kind, such as a product of units, converts to any kind of its
+// dimension.
+func kindsDiffer(a, b string) bool {
+	return a != "" && b != "" && a != b
+}
@@ -0,0 +1,30 @@
+package converter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHugeNumbersInErrors(t *testing.T) {
+	c := NewConverter(MustRegisterSystems())
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1e999999 m", "cannot convert 1e+999999 m: the result would be infinite"},
+		{"1 m + 1e999999", "incompatible dimensions: 'm' (length) and '1e+999999' (dimensionless)"},
+		{"1 m + 1/3", "incompatible dimensions: 'm' (length) and '1/3' (dimensionless)"},
+	}
+	for _, tt := range tests {
+		_, err := c.Process(tt.input)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Process(%q) error = %v, want %s", tt.input, err, tt.want)
+		}
+	}
+
+	_, err := c.Process("1e999999 m")
+	var infErr *InfiniteResultError
+	if !errors.As(err, &infErr) {
+		t.Errorf("Process(%q) error = %T, want *InfiniteResultError", "1e999999 m", err)
+	}
+}
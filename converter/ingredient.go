@@ -0,0 +1,140 @@
+package converter
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Ingredient is a substance that recipes measure both by volume and by
+// weight, such as flour. Its density converts one into the other, so "2 cups
+// of sugar in g" weighs the sugar.
+type Ingredient struct {
+	Name    string
+	Aliases []string
+	// Density is in grams per milliliter.
+	Density *big.Rat
+}
+
+func (i Ingredient) keys() []string {
+	return append([]string{i.Name}, i.Aliases...)
+}
+
+// BuiltinIngredients returns the ingredients that ship with the converter.
+// Densities of powders are as spooned into a cup and leveled, and of brown
+// sugar as packed.
+func BuiltinIngredients() []Ingredient {
+	return []Ingredient{
+		{Name: "water", Density: ratio("1")},
+		{Name: "milk", Density: ratio("1.03")},
+		{Name: "flour", Aliases: []string{"all purpose flour", "plain flour", "white flour"}, Density: ratio("0.53")},
+		{Name: "bread flour", Density: ratio("0.55")},
+		{Name: "whole wheat flour", Aliases: []string{"wholemeal flour"}, Density: ratio("0.51")},
+		{Name: "sugar", Aliases: []string{"granulated sugar", "white sugar", "caster sugar"}, Density: ratio("0.845")},
+		{Name: "brown sugar", Density: ratio("0.93")},
+		{Name: "powdered sugar", Aliases: []string{"icing sugar", "confectioners sugar"}, Density: ratio("0.51")},
+		{Name: "butter", Density: ratio("0.96")},
+		{Name: "oil", Aliases: []string{"vegetable oil", "olive oil"}, Density: ratio("0.92")},
+		{Name: "honey", Density: ratio("1.42")},
+		{Name: "maple syrup", Density: ratio("1.32")},
+		{Name: "rice", Aliases: []string{"white rice"}, Density: ratio("0.78")},
+		{Name: "oats", Aliases: []string{"rolled oats"}, Density: ratio("0.38")},
+		{Name: "cocoa", Aliases: []string{"cocoa powder"}, Density: ratio("0.36")},
+		{Name: "salt", Aliases: []string{"table salt"}, Density: ratio("1.2")},
+	}
+}
+
+// RegisterIngredients adds ingredients to the registry, replacing any that
+// answer to the same name or alias.
+func (r *Registry) RegisterIngredients(ingredients ...Ingredient) {
+	for _, ingredient := range ingredients {
+		for _, key := range ingredient.keys() {
+			r.ingredients[strings.ToLower(key)] = ingredient
+		}
+	}
+}
+
+// Ingredient looks up an ingredient by name or alias, ignoring case.
+func (r *Registry) Ingredient(name string) (Ingredient, bool) {
+	ingredient, ok := r.ingredients[strings.ToLower(strings.TrimSpace(name))]
+	return ingredient, ok
+}
+
+// Ingredients returns every registered ingredient, sorted by name.
+func (r *Registry) Ingredients() []Ingredient {
+	seen := make(map[string]bool)
+	var ingredients []Ingredient
+	for _, ingredient := range r.ingredients {
+		if !seen[ingredient.Name] {
+			seen[ingredient.Name] = true
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+	sort.Slice(ingredients, func(i, j int) bool { return ingredients[i].Name < ingredients[j].Name })
+	return ingredients
+}
+
+// ingredientKeys returns the names and aliases of every ingredient, folded to
+// lower case and sorted.
+func (r *Registry) ingredientKeys() []string {
+	keys := make([]string, 0, len(r.ingredients))
+	for key := range r.ingredients {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unknownIngredientError suggests up to three ingredients whose names are
+// close to the unknown one.
+func (c *Converter) unknownIngredientError(name string, pos int) error {
+	suggestions := suggest(name, c.registry.ingredientKeys(), func(key string) []string {
+		ingredient, _ := c.registry.Ingredient(key)
+		return []string{ingredient.Name}
+	})
+	return &UnknownIngredientError{Ingredient: name, Position: pos, Suggestions: suggestions}
+}
+
+// measuredAs converts a quantity of an ingredient between volume and mass
+// through the ingredient's density, and reports whether it could.
+func (q quantity) measuredAs(dim Dimension) (quantity, bool) {
+	if q.ingredient == nil {
+		return q, false
+	}
+	// g/mL is 1000 kg/m³
+	density := new(big.Rat).Mul(q.ingredient.Density, big.NewRat(1000, 1))
+	switch {
+	case q.dim == DimVolume && dim == DimMass:
+		// kg = m³ · kg/m³
+	case q.dim == DimMass && dim == DimVolume:
+		density.Inv(density)
+	default:
+		return q, false
+	}
+	factor, _ := density.Float64()
+
+	measured := quantity{value: q.value * factor, dim: dim, label: q.label, ingredient: q.ingredient, inexact: q.inexact, at: q.at}
+	if q.exact != nil {
+		measured.exact = new(big.Rat).Mul(q.exact, density)
+		measured.value, _ = measured.exact.Float64()
+	}
+	return measured, true
+}
+
+// checkIngredient reports why an ingredient cannot be registered alongside
+// the registered ones.
+func (r *Registry) checkIngredient(ingredient Ingredient) error {
+	if strings.TrimSpace(ingredient.Name) == "" {
+		return fmt.Errorf("ingredient without a name")
+	}
+	if ingredient.Density == nil || ingredient.Density.Sign() <= 0 {
+		return fmt.Errorf("ingredient '%s': density must be a positive number", ingredient.Name)
+	}
+	for _, key := range ingredient.keys() {
+		if other, exists := r.Ingredient(key); exists {
+			return fmt.Errorf("ingredient '%s' is already defined as %s", key, other.Name)
+		}
+	}
+	return nil
+}
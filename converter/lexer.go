@@ -0,0 +1,402 @@
+package converter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenUnit
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+// token is one lexeme of an expression. Pos is the byte offset of the token
+// in the original input.
+type token struct {
+	Kind  tokenKind
+	Text  string
+	Value *big.Rat
+	Pos   int
+}
+
+var textNumberMap = map[string]string{
+	"a": "1", "an": "1", "one": "1", "two": "2", "three": "3", "four": "4", "five": "5",
+	"six": "6", "seven": "7", "eight": "8", "nine": "9", "ten": "10", "half": "1/2",
+}
+
+type lexer struct {
+	input  []rune
+	pos    int
+	offset int // byte offset of pos
+	tokens []token
+}
+
+// lex splits an expression into numbers, unit runs, operators and
+// parentheses. It understands the natural-language parts of the input as
+// well: text numbers ("two", "half"), "and"/"&" for addition, "and a half",
+// a leading "convert" and "delta" before a temperature unit.
+func lex(input string) ([]token, error) {
+	l := &lexer{input: []rune(input)}
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		switch {
+		case unicode.IsSpace(r):
+			l.advance(1)
+		case unicode.IsDigit(r) || (r == '.' && unicode.IsDigit(l.peek(1))):
+			if err := l.number(); err != nil {
+				return nil, err
+			}
+		case unicode.Is(unicode.Sc, r):
+			if err := l.currency(); err != nil {
+				return nil, err
+			}
+		case unicode.IsLetter(r) || strings.ContainsRune("°′″%", r):
+			l.word()
+		case strings.ContainsRune("+-*/×·&", r):
+			text := string(r)
+			switch r {
+			case '×', '·':
+				text = "*"
+			case '&':
+				text = "+"
+			}
+			l.emit(tokenOperator, text, l.offset)
+			l.advance(1)
+		case r == '(':
+			l.emit(tokenLParen, "(", l.offset)
+			l.advance(1)
+		case r == ')':
+			l.emit(tokenRParen, ")", l.offset)
+			l.advance(1)
+		default:
+			return nil, &SyntaxError{Message: fmt.Sprintf("unexpected character '%c'", r), Position: l.offset}
+		}
+	}
+	return andAHalf(l.tokens), nil
+}
+
+// andAHalf reads a half added on its own as half of what comes before it:
+// "two and a half cups" is 2½ cups, and "a cup and a half" adds half a cup
+// to the cup. A half followed by its own unit, as in "a cup and half a
+// pint", is left alone.
+func andAHalf(tokens []token) []token {
+	var out []token
+	for i, tok := range tokens {
+		n := len(out)
+		if tok.Kind != tokenNumber || tok.Text != "half" || n < 2 || out[n-1].Kind != tokenOperator || out[n-1].Text != "+" {
+			out = append(out, tok)
+			continue
+		}
+		switch before := out[n-2]; {
+		case before.Kind == tokenNumber:
+			out[n-2].Value = new(big.Rat).Add(before.Value, tok.Value)
+			out = out[:n-1]
+		case before.Kind == tokenUnit && (i+1 == len(tokens) || tokens[i+1].Kind != tokenUnit || isTargetKeyword(tokens[i+1])):
+			start := n - 2
+			for start > 0 && out[start-1].Kind == tokenUnit {
+				start--
+			}
+			out = append(append(out, tok), out[start:n-1]...)
+		default:
+			out = append(out, tok)
+		}
+	}
+	return out
+}
+
+func (l *lexer) peek(n int) rune {
+	if l.pos+n < len(l.input) {
+		return l.input[l.pos+n]
+	}
+	return 0
+}
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.pos < len(l.input); i++ {
+		l.offset += len(string(l.input[l.pos]))
+		l.pos++
+	}
+}
+
+func (l *lexer) emit(kind tokenKind, text string, pos int) {
+	l.tokens = append(l.tokens, token{Kind: kind, Text: text, Pos: pos})
+}
+
+// number reads a decimal, scientific or fractional number. A fraction must
+// be written without spaces ("1/2"); "1 / 2" divides. A fraction following
+// a whole number makes a mixed number ("1 1/4").
+func (l *lexer) number() error {
+	start, startOffset := l.pos, l.offset
+	for unicode.IsDigit(l.peek(0)) || l.peek(0) == '.' {
+		l.advance(1)
+	}
+	if r := l.peek(0); r == 'e' || r == 'E' {
+		n := 1
+		if s := l.peek(1); s == '+' || s == '-' {
+			n = 2
+		}
+		if unicode.IsDigit(l.peek(n)) {
+			l.advance(n)
+			for unicode.IsDigit(l.peek(0)) {
+				l.advance(1)
+			}
+		}
+	}
+	if l.peek(0) == '/' && unicode.IsDigit(l.peek(1)) && isInteger(l.input[start:l.pos]) {
+		l.advance(1)
+		for unicode.IsDigit(l.peek(0)) {
+			l.advance(1)
+		}
+	}
+
+	text := string(l.input[start:l.pos])
+	value, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return &InvalidNumberError{Text: text, Position: startOffset}
+	}
+	if angle, err := l.angle(value, start, startOffset); angle || err != nil {
+		return err
+	}
+
+	// "1 1/4": the whole part was the previous token
+	if n := len(l.tokens); n > 0 && strings.Contains(text, "/") {
+		prev := &l.tokens[n-1]
+		if prev.Kind == tokenNumber && isInteger([]rune(prev.Text)) {
+			prev.Value = new(big.Rat).Add(prev.Value, value)
+			prev.Text += " " + text
+			return nil
+		}
+	}
+
+	l.tokens = append(l.tokens, token{Kind: tokenNumber, Text: text, Value: value, Pos: startOffset})
+	return nil
+}
+
+// dmsMarks are the marks of minutes and seconds that may follow each mark of
+// degrees.
+var dmsMarks = map[rune][2]string{
+	'°': {"'′", "\"″"},
+	'd': {"m", "s"},
+}
+
+// angle reads the minutes and seconds of an angle written in degrees, minutes
+// and seconds, such as 40°26'46" or "12d 30m", once its whole degrees have
+// been read. The angle becomes a number of degrees. It reports whether there
+// were minutes; if not, the lexer is left where it was, so "12d" stays days.
+func (l *lexer) angle(degrees *big.Rat, start, startOffset int) (bool, error) {
+	marks, ok := dmsMarks[l.peek(0)]
+	if !ok || !isInteger(l.input[start:l.pos]) {
+		return false, nil
+	}
+	pos, offset := l.pos, l.offset
+	l.advance(1)
+
+	value := new(big.Rat).Set(degrees)
+	parts := 0
+	for i, mark := range marks {
+		part, ok := l.dmsPart(mark)
+		if !ok {
+			break
+		}
+		parts++
+		text := string(l.input[start:l.pos])
+		if part.Cmp(big.NewRat(60, 1)) >= 0 {
+			return false, &InvalidNumberError{Text: text, Position: startOffset}
+		}
+		// Minutes are 1/60 of a degree and seconds 1/3600
+		scale := big.NewRat(60, 1)
+		if i == 1 {
+			scale = big.NewRat(3600, 1)
+		}
+		value.Add(value, part.Quo(part, scale))
+	}
+	if parts == 0 {
+		l.pos, l.offset = pos, offset
+		return false, nil
+	}
+
+	text := string(l.input[start:l.pos])
+	l.tokens = append(l.tokens, token{Kind: tokenNumber, Text: text, Value: value, Pos: startOffset})
+	l.emit(tokenUnit, "°", offset)
+	return true, nil
+}
+
+// dmsPart reads the minutes or seconds of an angle, a number followed by one
+// of marks. Nothing is read unless both are there.
+func (l *lexer) dmsPart(marks string) (*big.Rat, bool) {
+	pos, offset := l.pos, l.offset
+	l.skipSpaces()
+	numStart := l.pos
+	for unicode.IsDigit(l.peek(0)) || l.peek(0) == '.' {
+		l.advance(1)
+	}
+	part, ok := new(big.Rat).SetString(string(l.input[numStart:l.pos]))
+	mark := l.peek(0)
+	// A letter mark must end the word: "30min" is not 30 arcminutes
+	if !ok || mark == 0 || !strings.ContainsRune(marks, mark) || unicode.IsLetter(mark) && unicode.IsLetter(l.peek(1)) {
+		l.pos, l.offset = pos, offset
+		return nil, false
+	}
+	l.advance(1)
+	return part, true
+}
+
+func isInteger(rs []rune) bool {
+	for _, r := range rs {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return len(rs) > 0
+}
+
+// word reads a unit run such as "km", "m/s²", "kg*m/s^2" or "W/(m²·K)", or a
+// natural-language word that stands for a number or an operator.
+func (l *lexer) word() {
+	start, startOffset := l.pos, l.offset
+	depth := 0
+run:
+	for l.pos < len(l.input) {
+		r := l.peek(0)
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("°²³′″%", r) || unicode.Is(unicode.Sc, r):
+			l.advance(1)
+		case r == '^':
+			n := 1
+			if l.peek(1) == '-' {
+				n = 2
+			}
+			if !unicode.IsDigit(l.peek(n)) {
+				break run
+			}
+			l.advance(n)
+		case strings.ContainsRune("/*·", r):
+			// Only joins units when written without spaces ("km/h")
+			next := l.peek(1)
+			if !unicode.IsLetter(next) && !unicode.IsDigit(next) && next != '(' && next != '°' {
+				break run
+			}
+			l.advance(1)
+		case r == '(' && l.pos > start:
+			depth++
+			l.advance(1)
+		case r == ')' && depth > 0:
+			depth--
+			l.advance(1)
+		default:
+			break run
+		}
+	}
+
+	text := string(l.input[start:l.pos])
+	lower := strings.ToLower(text)
+
+	switch {
+	case lower == "convert" && len(l.tokens) == 0:
+		return
+	case lower == "and":
+		l.emit(tokenOperator, "+", startOffset)
+		return
+	case lower == "delta":
+		// "delta c" is the temperature difference Δ°C
+		l.skipSpaces()
+		if unicode.IsLetter(l.peek(0)) || l.peek(0) == '°' {
+			unitOffset := len(l.tokens)
+			l.word()
+			if len(l.tokens) > unitOffset && l.tokens[unitOffset].Kind == tokenUnit {
+				l.tokens[unitOffset].Text = "Δ" + l.tokens[unitOffset].Text
+				l.tokens[unitOffset].Pos = startOffset
+			}
+			return
+		}
+	}
+
+	if numStr, ok := textNumberMap[lower]; ok && l.startsQuantity(lower) {
+		// "a half cup" and "half a cup" are both one half
+		if n := len(l.tokens); n > 0 && l.tokens[n-1].Kind == tokenNumber && isHalfPair(l.tokens[n-1].Text, lower) {
+			l.tokens[n-1].Value = ratio("1/2")
+			l.tokens[n-1].Text = "half"
+			return
+		}
+		value := ratio(numStr)
+		l.tokens = append(l.tokens, token{Kind: tokenNumber, Text: lower, Value: value, Pos: startOffset})
+		return
+	}
+
+	l.emit(tokenUnit, text, startOffset)
+}
+
+// currency reads a currency symbol. Written before an amount, as in "$3.50",
+// it is the unit of the amount; otherwise it starts a word ("€/L").
+func (l *lexer) currency() error {
+	if r := l.peek(1); !unicode.IsDigit(r) && !(r == '.' && unicode.IsDigit(l.peek(2))) {
+		l.word()
+		return nil
+	}
+	symbol, symbolOffset := string(l.peek(0)), l.offset
+	l.advance(1)
+	if err := l.number(); err != nil {
+		return err
+	}
+	// "$1 1/2": the fraction joins the amount before the symbol follows it
+	if amount := l.tokens[len(l.tokens)-1]; isInteger([]rune(amount.Text)) && l.fractionAhead() {
+		l.skipSpaces()
+		if err := l.number(); err != nil {
+			return err
+		}
+	}
+	l.emit(tokenUnit, symbol, symbolOffset)
+	return nil
+}
+
+// fractionAhead reports whether spaces and then a fraction such as "1/2"
+// come next.
+func (l *lexer) fractionAhead() bool {
+	i := 0
+	for unicode.IsSpace(l.peek(i)) {
+		i++
+	}
+	if i == 0 || !unicode.IsDigit(l.peek(i)) {
+		return false
+	}
+	for unicode.IsDigit(l.peek(i)) {
+		i++
+	}
+	return l.peek(i) == '/' && unicode.IsDigit(l.peek(i+1))
+}
+
+// startsQuantity reports whether a text number is followed by something it
+// can count. "a" is only a number in "a foot", not as the unit in "5 a" or
+// "5 A in mA".
+func (l *lexer) startsQuantity(word string) bool {
+	if word != "a" && word != "an" {
+		return true
+	}
+	if n := len(l.tokens); n > 0 && l.tokens[n-1].Kind == tokenNumber && !isHalfPair(l.tokens[n-1].Text, word) {
+		return false
+	}
+	for i := l.pos; i < len(l.input); i++ {
+		if !unicode.IsSpace(l.input[i]) {
+			return i > l.pos && (unicode.IsLetter(l.input[i]) || l.input[i] == '°')
+		}
+	}
+	return false
+}
+
+func isHalfPair(a, b string) bool {
+	article := func(s string) bool { return s == "a" || s == "an" }
+	return a == "half" && article(b) || article(a) && b == "half"
+}
+
+func (l *lexer) skipSpaces() {
+	for unicode.IsSpace(l.peek(0)) {
+		l.advance(1)
+	}
+}
@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale chooses between customary units that go by the same name, such as
+// the US and the imperial gallon.
+type Locale string
+
+const (
+	LocaleUS Locale = "US"
+	LocaleUK Locale = "UK"
+)
+
+// ParseLocale reads a locale written as "us", "uk", "en-GB" or "imperial".
+func ParseLocale(s string) (Locale, error) {
+	switch strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "_", "-")) {
+	case "us", "usa", "en-us":
+		return LocaleUS, nil
+	case "uk", "gb", "en-gb", "imperial":
+		return LocaleUK, nil
+	}
+	return "", fmt.Errorf("unknown locale: '%s' (use US or UK)", s)
+}
+
+// SetLocale decides which unit a name shared by the US and imperial systems
+// refers to: with LocaleUK "2 pints" are imperial pints. The default is
+// LocaleUS. Names that spell out the system, such as "us gal" and "imp gal",
+// mean the same in every locale.
+func (c *Converter) SetLocale(locale Locale) {
+	c.locale = locale
+}
+
+// lookup finds the units a key refers to in the converter's locale, with
+// screen units measured at its display settings. Units of other locales are
+// dropped when a unit of this locale answers to the key, so "gallon" is one
+// gallon, while "imp gal" is found in any locale.
+func (c *Converter) lookup(s string) ([]Unit, bool) {
+	units, ok := c.registry.Lookup(s)
+	if !ok {
+		return nil, false
+	}
+	var all, kept []Unit
+	local := false
+	for _, unit := range units {
+		unit = c.scaled(unit)
+		all = append(all, unit)
+		if unit.Locale == "" || unit.Locale == c.locale {
+			kept = append(kept, unit)
+			local = local || unit.Locale == c.locale
+		}
+	}
+	if !local {
+		return all, true
+	}
+	return kept, true
+}
+
+// localesDiffer reports whether two locales are known and different, so
+// that their units may share a name.
+func localesDiffer(a, b Locale) bool {
+	return a != "" && b != "" && a != b
+}
@@ -0,0 +1,43 @@
+package converter
+
+import "testing"
+
+func TestLocales(t *testing.T) {
+	c := NewConverter(MustRegisterSystems())
+	checkResults(t, c, []resultTest{
+		{"2 pints in ml", 946.352946, "mL", ""},
+		{"1 ton in kg", 907.18474, "kg", ""},
+		{"1 imp gal in us gal", 1.200949925504855, "gal", ""},
+	})
+
+	c.SetLocale(LocaleUK)
+	checkResults(t, c, []resultTest{
+		{"2 pints in ml", 1136.5225, "mL", ""},
+		{"1 gallon in us gal", 1.200949925504855, "gal", ""},
+		{"1 ton in kg", 1016.0469088, "kg", ""},
+		{"10 fl oz in ml", 284.130625, "mL", ""},
+		// Names that spell out the system mean the same in every locale
+		{"1 us gal in L", 3.785411784, "L", ""},
+	})
+}
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Locale
+	}{
+		{"us", LocaleUS},
+		{"en_US", LocaleUS},
+		{"UK", LocaleUK},
+		{"en-GB", LocaleUK},
+		{"imperial", LocaleUK},
+	}
+	for _, tt := range tests {
+		if got, err := ParseLocale(tt.input); err != nil || got != tt.want {
+			t.Errorf("ParseLocale(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+	if _, err := ParseLocale("fr"); err == nil {
+		t.Errorf("ParseLocale(%q) succeeded, want an error", "fr")
+	}
+}
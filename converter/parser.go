@@ -0,0 +1,284 @@
+package converter
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// An expression is parsed into a tree of nodes before any unit is looked up.
+// The grammar, from lowest to highest precedence, is
+//
+//	expr    = term { ("+" | "-") term | term }
+//	term    = unary { ("*" | "/") unary }
+//	unary   = "-" unary | primary
+//	primary = number [unit] | unit | "(" expr ")"
+//
+// Two quantities written side by side add up ("5 ft 3 in"), and a number on
+// its own is a bare scalar ("3 * 2 ft").
+type node interface {
+	pos() int
+}
+
+// numberNode is a bare scalar.
+type numberNode struct {
+	value *big.Rat
+	at    int
+}
+
+// quantityNode is a number with a unit, such as "2 ft". A unit written
+// without a number counts once. The ingredient is what the unit measures out,
+// as in "2 cups of sugar".
+type quantityNode struct {
+	value        *big.Rat
+	unit         string
+	at           int
+	unitAt       int
+	ingredient   string
+	ingredientAt int
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+	at          int
+}
+
+type negateNode struct {
+	operand node
+	at      int
+}
+
+func (n *numberNode) pos() int   { return n.at }
+func (n *quantityNode) pos() int { return n.at }
+func (n *binaryNode) pos() int   { return n.at }
+func (n *negateNode) pos() int   { return n.at }
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse builds the expression tree for a token list, which must not include
+// the target unit.
+func parse(tokens []token) (node, error) {
+	p := &parser{tokens: tokens}
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+		return nil, &SyntaxError{Message: fmt.Sprintf("unexpected '%s'", tok.Text), Position: tok.Pos}
+	}
+	return n, nil
+}
+
+func (p *parser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *parser) peekOperator(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok == nil || tok.Kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.Text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// startsPrimary reports whether the next token can begin a quantity, which
+// makes it an implicit addition.
+func (p *parser) startsPrimary() bool {
+	tok := p.peek()
+	return tok != nil && (tok.Kind == tokenNumber || tok.Kind == tokenUnit || tok.Kind == tokenLParen)
+}
+
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peekOperator("+", "-")
+		at := p.pos
+		switch {
+		case ok:
+			at = p.tokens[p.pos].Pos
+			p.pos++
+		case p.startsPrimary():
+			op, at = "+", p.tokens[p.pos].Pos
+		default:
+			return left, nil
+		}
+
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right, at: at}
+	}
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peekOperator("*", "/")
+		if !ok {
+			return left, nil
+		}
+		at := p.tokens[p.pos].Pos
+		p.pos++
+
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right, at: at}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if _, ok := p.peekOperator("-"); !ok {
+		return p.primary()
+	}
+	at := p.tokens[p.pos].Pos
+	p.pos++
+
+	// "-40 F" is a negative temperature, not the negation of 40 °F
+	if tok := p.peek(); tok != nil && tok.Kind == tokenNumber {
+		tok.Value = new(big.Rat).Neg(tok.Value)
+		tok.Pos = at
+		return p.primary()
+	}
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &negateNode{operand: operand, at: at}, nil
+}
+
+func (p *parser) primary() (node, error) {
+	tok := p.peek()
+	if tok == nil {
+		return nil, &SyntaxError{Message: "unexpected end of expression", Position: p.end()}
+	}
+
+	switch tok.Kind {
+	case tokenNumber:
+		p.pos++
+		if next := p.peek(); next != nil && next.Kind == tokenUnit {
+			n := &quantityNode{value: tok.Value, at: tok.Pos, unitAt: next.Pos}
+			p.unit(n)
+			return n, nil
+		}
+		return &numberNode{value: tok.Value, at: tok.Pos}, nil
+	case tokenUnit:
+		n := &quantityNode{value: big.NewRat(1, 1), at: tok.Pos, unitAt: tok.Pos}
+		p.unit(n)
+		return n, nil
+	case tokenLParen:
+		p.pos++
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.Kind != tokenRParen {
+			return nil, &SyntaxError{Message: "missing ')' for '('", Position: tok.Pos}
+		}
+		p.pos++
+		return n, nil
+	}
+	return nil, &SyntaxError{Message: fmt.Sprintf("unexpected '%s'", tok.Text), Position: tok.Pos}
+}
+
+// end is the position just past the last token.
+func (p *parser) end() int {
+	last := p.tokens[len(p.tokens)-1]
+	return last.Pos + len(last.Text)
+}
+
+// unit reads a run of unit words such as "fl oz", "miles per hour" or
+// "kWh per 100 km" as the unit of n. An "of" splits off the ingredient, as in
+// "cups of sugar"; the resolver puts the run back together for units that
+// are spelled with one, such as "ton of refrigeration".
+func (p *parser) unit(n *quantityNode) {
+	var words []token
+	for tok := p.peek(); tok != nil; tok = p.peek() {
+		if tok.Kind == tokenNumber && len(words) > 0 && strings.EqualFold(words[len(words)-1].Text, "per") {
+			words = append(words, *tok)
+			p.pos++
+			continue
+		}
+		if tok.Kind != tokenUnit {
+			break
+		}
+		words = append(words, *tok)
+		p.pos++
+	}
+
+	n.unit = joinTokens(words)
+	for i := 1; i < len(words)-1; i++ {
+		if strings.EqualFold(words[i].Text, "of") {
+			n.unit = joinTokens(words[:i])
+			n.ingredient = joinTokens(words[i+1:])
+			n.ingredientAt = words[i+1].Pos
+			return
+		}
+	}
+}
+
+// splitTarget separates the "in <unit>" or "to <unit>" clause from the end of
+// the tokens. The last "in" or "to" followed by a unit and no further
+// additions starts the target, so "5 in in cm" converts inches.
+func splitTarget(tokens []token) ([]token, []token) {
+	for i := len(tokens) - 2; i > 0; i-- {
+		word := tokens[i]
+		if word.Kind != tokenUnit || !strings.EqualFold(word.Text, "in") && !strings.EqualFold(word.Text, "to") {
+			continue
+		}
+		if tokens[i+1].Kind != tokenUnit {
+			continue
+		}
+		additive := false
+		for _, tok := range tokens[i+1:] {
+			if tok.Kind == tokenOperator && (tok.Text == "+" || tok.Text == "-") {
+				additive = true
+			}
+		}
+		if !additive {
+			return tokens[:i], tokens[i+1:]
+		}
+	}
+	return tokens, nil
+}
+
+// missingTarget reports an expression without a target that ends in "in" or
+// "to" after a unit, as in "5 km in". "5 in" is five inches, and with a
+// target elsewhere "1 lbf in in N m" is a pound-force inch.
+func missingTarget(tokens []token) error {
+	if n := len(tokens); n >= 2 && isTargetKeyword(tokens[n-1]) && tokens[n-2].Kind == tokenUnit {
+		last := tokens[n-1]
+		return &SyntaxError{Message: fmt.Sprintf("expected a unit after '%s'", last.Text), Position: last.Pos}
+	}
+	return nil
+}
+
+// joinTokens writes tokens back out as text, as for a target unit.
+func joinTokens(tokens []token) string {
+	texts := make([]string, len(tokens))
+	for i, tok := range tokens {
+		texts[i] = tok.Text
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,143 @@
+package converter
+
+import (
+	"math/big"
+	"strings"
+)
+
+type siPrefix struct {
+	Name   string
+	Symbol string
+	Factor string
+}
+
+var siPrefixes = []siPrefix{
+	{"quecto", "q", "1e-30"},
+	{"ronto", "r", "1e-27"},
+	{"yocto", "y", "1e-24"},
+	{"zepto", "z", "1e-21"},
+	{"atto", "a", "1e-18"},
+	{"femto", "f", "1e-15"},
+	{"pico", "p", "1e-12"},
+	{"nano", "n", "1e-9"},
+	{"micro", "µ", "1e-6"},
+	{"milli", "m", "1e-3"},
+	{"centi", "c", "1e-2"},
+	{"deci", "d", "1e-1"},
+	{"deca", "da", "1e1"},
+	{"hecto", "h", "1e2"},
+	{"kilo", "k", "1e3"},
+	{"mega", "M", "1e6"},
+	{"giga", "G", "1e9"},
+	{"tera", "T", "1e12"},
+	{"peta", "P", "1e15"},
+	{"exa", "E", "1e18"},
+	{"zetta", "Z", "1e21"},
+	{"yotta", "Y", "1e24"},
+	{"ronna", "R", "1e27"},
+	{"quetta", "Q", "1e30"},
+}
+
+// binaryPrefixes are the IEC prefixes for powers of 1024.
+var binaryPrefixes = []siPrefix{
+	{"kibi", "Ki", "1024"},
+	{"mebi", "Mi", "1048576"},
+	{"gibi", "Gi", "1073741824"},
+	{"tebi", "Ti", "1099511627776"},
+	{"pebi", "Pi", "1125899906842624"},
+	{"exbi", "Ei", "1152921504606846976"},
+	{"zebi", "Zi", "1180591620717411303424"},
+	{"yobi", "Yi", "1208925819614629174706176"},
+}
+
+// PrefixSet selects the prefixes a Prefixable unit is registered with.
+type PrefixSet int
+
+const (
+	// SIPrefixes are every SI prefix, quecto to quetta.
+	SIPrefixes PrefixSet = iota
+	// DataPrefixes are the SI multiples from kilo up and the IEC binary
+	// prefixes, as used for bits and bytes. There is no millibyte, and "mb"
+	// must not read as one.
+	DataPrefixes
+	// MultiplePrefixes are the SI multiples from kilo up, for units such as
+	// the hertz whose submultiples are rare enough that "mhz" should read as
+	// megahertz.
+	MultiplePrefixes
+)
+
+func (s PrefixSet) prefixes() []siPrefix {
+	if s == SIPrefixes {
+		return siPrefixes
+	}
+	var prefixes []siPrefix
+	for _, prefix := range siPrefixes {
+		if factor := ratio(prefix.Factor); factor.Cmp(ratio("1000")) >= 0 {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if s == DataPrefixes {
+		prefixes = append(prefixes, binaryPrefixes...)
+	}
+	return prefixes
+}
+
+// microVariants are the ASCII and Greek-letter spellings accepted for the
+// micro sign.
+var microVariants = []string{"u", "μ"}
+
+// expandPrefixes returns the system's units together with every prefixed
+// variant of its Prefixable units. Units the system already defines by name,
+// such as a hand-tuned base unit, are left as they are.
+func expandPrefixes(units map[string]Unit) map[string]Unit {
+	expanded := make(map[string]Unit, len(units))
+	for name, unit := range units {
+		expanded[name] = unit
+	}
+
+	for _, unit := range units {
+		if !unit.Prefixable {
+			continue
+		}
+		for _, prefix := range unit.Prefixes.prefixes() {
+			prefixed := prefixUnit(prefix, unit)
+			if _, exists := expanded[prefixed.Name]; !exists {
+				expanded[prefixed.Name] = prefixed
+			}
+		}
+	}
+	return expanded
+}
+
+// prefixUnit applies a prefix to a unit. Short aliases ("l", "sec") take
+// the prefix symbol and longer ones ("litre") take the prefix name.
+func prefixUnit(prefix siPrefix, unit Unit) Unit {
+	symbols := []string{prefix.Symbol}
+	if prefix.Symbol == "µ" {
+		symbols = append(symbols, microVariants...)
+	}
+
+	var aliases []string
+	for _, alias := range unit.Aliases {
+		if len(alias) > 3 {
+			aliases = append(aliases, prefix.Name+strings.ToLower(alias))
+			continue
+		}
+		for _, symbol := range symbols {
+			aliases = append(aliases, symbol+alias)
+		}
+	}
+	for _, symbol := range symbols[1:] {
+		aliases = append(aliases, symbol+unit.Symbol)
+	}
+
+	return Unit{
+		Name:    strings.ToUpper(prefix.Name[:1]) + prefix.Name[1:] + strings.ToLower(unit.Name),
+		Symbol:  prefix.Symbol + unit.Symbol,
+		Aliases: aliases,
+		Factor:  new(big.Rat).Mul(ratio(prefix.Factor), unit.Factor),
+		Inexact: unit.Inexact,
+
+		prefix: prefix.Name,
+	}
+}
@@ -0,0 +1,317 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Registry is the catalog of units a Converter understands. It keeps the
+// registered systems in order and indexes their units by name, symbol and
+// alias, ignoring case.
+type Registry struct {
+	systems     []UnitSystem
+	units       map[string][]Unit
+	ingredients map[string]Ingredient
+	ratesAsOf   time.Time
+}
+
+// AliasCollision records a lookup key that more than one unit answers to,
+// such as "oz" for both Fluid Ounce and Ounces.
+type AliasCollision struct {
+	Alias string
+	Units []Unit
+}
+
+func NewRegistry() *Registry {
+	return &Registry{units: make(map[string][]Unit), ingredients: make(map[string]Ingredient)}
+}
+
+// BuiltinSystems returns the unit systems that ship with the converter.
+func BuiltinSystems() []UnitSystem {
+	return []UnitSystem{
+		NewVolumeSystem(),
+		NewLengthSystem(),
+		NewWeightSystem(),
+		NewTemperatureSystem(),
+		NewAreaSystem(),
+		NewSpeedSystem(),
+		NewTimeSystem(),
+		NewEnergySystem(),
+		NewPowerSystem(),
+		NewPressureSystem(),
+		NewForceSystem(),
+		NewTorqueSystem(),
+		NewDataSystem(),
+		NewDataRateSystem(),
+		NewAngleSystem(),
+		NewFrequencySystem(),
+		NewFuelEconomySystem(),
+		NewDensitySystem(),
+		NewConcentrationSystem(),
+		NewCurrentSystem(),
+		NewChargeSystem(),
+		NewVoltageSystem(),
+		NewResistanceSystem(),
+		NewCapacitanceSystem(),
+		NewConductanceSystem(),
+		NewTypographySystem(),
+	}
+}
+
+// RegisterSystems returns a registry of the given systems together with the
+// aliases their units share.
+func RegisterSystems(systems ...UnitSystem) (*Registry, []AliasCollision) {
+	r := NewRegistry()
+	r.Register(systems...)
+	return r, r.Collisions()
+}
+
+// MustRegisterSystems registers the built-in systems and ingredients. It
+// panics when two units of the same dimension share an alias with the same
+// case, since neither context nor case could tell them apart.
+func MustRegisterSystems() *Registry {
+	r, collisions := RegisterSystems(BuiltinSystems()...)
+	if err := checkDistinct(collisions); err != nil {
+		panic(err.Error())
+	}
+	r.RegisterIngredients(BuiltinIngredients()...)
+	return r
+}
+
+// checkDistinct reports the first collision between units of the same
+// dimension that share a key with the same case.
+func checkDistinct(collisions []AliasCollision) error {
+	for _, collision := range collisions {
+		for i, unit := range collision.Units {
+			for _, other := range collision.Units[:i] {
+				if other.Dimension == unit.Dimension && !localesDiffer(other.Locale, unit.Locale) && other.sharesKey(unit) {
+					return fmt.Errorf("alias '%s' is shared by %s and %s", collision.Alias, other.Name, unit.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Register adds systems to the registry, along with the SI-prefixed variants
+// of their Prefixable units. Every unit is indexed by its lowercased aliases,
+// name and symbol; keys shared by several units keep all of them as
+// candidates, in registration order. A system named like one that is already
+// registered adds its units to it.
+func (r *Registry) Register(systems ...UnitSystem) {
+	for _, system := range systems {
+		registered := r.system(system.Name)
+		if registered == nil {
+			r.systems = append(r.systems, UnitSystem{
+				Name:      system.Name,
+				BaseUnit:  system.BaseUnit,
+				Dimension: system.Dimension,
+				Kind:      system.Kind,
+				Units:     make(map[string]Unit),
+			})
+			registered = &r.systems[len(r.systems)-1]
+		}
+
+		units := expandPrefixes(system.Units)
+		names := make([]string, 0, len(units))
+		for name := range units {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			unit := units[name].withFactorFuncs()
+			unit.Dimension = registered.Dimension
+			unit.Kind = registered.Kind
+			unit.System = registered.Name
+			registered.Units[name] = unit
+			for _, key := range unit.keys() {
+				key = strings.ToLower(key)
+				if !containsUnit(r.units[key], unit) {
+					r.units[key] = append(r.units[key], unit)
+				}
+			}
+		}
+	}
+}
+
+// unregister removes the units of a system that remove selects, and the
+// system itself once none of its units are left.
+func (r *Registry) unregister(name string, remove func(Unit) bool) {
+	system := r.system(name)
+	if system == nil {
+		return
+	}
+	removed := system.Name
+	for key, units := range r.units {
+		var kept []Unit
+		for _, unit := range units {
+			if unit.System != removed || !remove(unit) {
+				kept = append(kept, unit)
+			}
+		}
+		if len(kept) == 0 {
+			delete(r.units, key)
+		} else {
+			r.units[key] = kept
+		}
+	}
+	for unitName, unit := range system.Units {
+		if remove(unit) {
+			delete(system.Units, unitName)
+		}
+	}
+	if len(system.Units) > 0 {
+		return
+	}
+	for i := range r.systems {
+		if r.systems[i].Name == removed {
+			r.systems = append(r.systems[:i:i], r.systems[i+1:]...)
+			break
+		}
+	}
+}
+
+func (r *Registry) system(name string) *UnitSystem {
+	for i := range r.systems {
+		if strings.EqualFold(r.systems[i].Name, name) {
+			return &r.systems[i]
+		}
+	}
+	return nil
+}
+
+// Lookup returns every unit a name, symbol or alias refers to. Lookup ignores
+// case, except that a unit whose symbol or alias matches exactly wins over
+// others of the same dimension ("Ms" is a megasecond, "ms" a millisecond).
+func (r *Registry) Lookup(s string) ([]Unit, bool) {
+	units, ok := r.units[strings.ToLower(s)]
+	if !ok {
+		return nil, false
+	}
+	return preferExactCase(s, units), true
+}
+
+func preferExactCase(s string, units []Unit) []Unit {
+	exact := make(map[Dimension]bool)
+	for _, unit := range units {
+		if unit.hasKey(s) {
+			exact[unit.Dimension] = true
+		}
+	}
+
+	var preferred []Unit
+	for _, unit := range units {
+		if !exact[unit.Dimension] || unit.hasKey(s) {
+			preferred = append(preferred, unit)
+		}
+	}
+	return preferred
+}
+
+// Systems returns the registered systems in registration order. Their Units
+// include the prefixed variants.
+func (r *Registry) Systems() []UnitSystem {
+	return append([]UnitSystem(nil), r.systems...)
+}
+
+// System returns the system with the given name, ignoring case.
+func (r *Registry) System(name string) (UnitSystem, bool) {
+	if system := r.system(name); system != nil {
+		return *system, true
+	}
+	return UnitSystem{}, false
+}
+
+// BaseUnit returns the unit every factor of the named system is relative to.
+// A system that extends another of its dimension, as Typography extends
+// Length, shares the other's base unit.
+func (r *Registry) BaseUnit(system string) (Unit, bool) {
+	s := r.system(system)
+	if s == nil {
+		return Unit{}, false
+	}
+	for _, other := range r.systems {
+		if other.Dimension != s.Dimension {
+			continue
+		}
+		if unit, ok := other.Units[s.BaseUnit]; ok {
+			return unit, true
+		}
+	}
+	return Unit{}, false
+}
+
+// coherentUnit returns the base unit of the system of a dimension, if one
+// system without a kind has it. Energy has none: a product of newtons and
+// meters could be torque too.
+func (r *Registry) coherentUnit(dim Dimension) (Unit, bool) {
+	for _, system := range r.systems {
+		if system.Dimension == dim && system.Kind == "" {
+			return r.BaseUnit(system.Name)
+		}
+	}
+	return Unit{}, false
+}
+
+// absoluteUnit returns the absolute unit a gauge unit reads in, such as the
+// psi of psig: the unit of its dimension with the same Factor and no Offset.
+func (r *Registry) absoluteUnit(gauge Unit) (Unit, bool) {
+	for _, unit := range r.Units() {
+		if unit.Dimension == gauge.Dimension && !unit.Affine && unit.Offset == nil &&
+			unit.Factor != nil && unit.Factor.Cmp(gauge.Factor) == 0 {
+			return unit, true
+		}
+	}
+	return Unit{}, false
+}
+
+// Units returns every registered unit, by system in registration order and
+// by name within a system.
+func (r *Registry) Units() []Unit {
+	var units []Unit
+	for _, system := range r.systems {
+		names := make([]string, 0, len(system.Units))
+		for name := range system.Units {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			units = append(units, system.Units[name])
+		}
+	}
+	return units
+}
+
+// Aliases returns every lookup key in sorted order. Keys are lowercase.
+func (r *Registry) Aliases() []string {
+	aliases := make([]string, 0, len(r.units))
+	for alias := range r.units {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// Collisions returns the lookup keys shared by more than one unit, sorted by
+// key.
+func (r *Registry) Collisions() []AliasCollision {
+	var collisions []AliasCollision
+	for _, alias := range r.Aliases() {
+		if units := r.units[alias]; len(units) > 1 {
+			collisions = append(collisions, AliasCollision{Alias: alias, Units: units})
+		}
+	}
+	return collisions
+}
+
+func containsUnit(units []Unit, unit Unit) bool {
+	for _, u := range units {
+		if u.Name == unit.Name && u.Dimension == unit.Dimension {
+			return true
+		}
+	}
+	return false
+}
@@ -1,169 +1,411 @@
 package converter
 
-import "strings"
+import (
+	"fmt"
+	"math/big"
+)
 
 type Unit struct {
-	Name         string
-	Symbol       string
-	Aliases      []string
+	Name      string
+	Symbol    string
+	Aliases   []string
+	Dimension Dimension
+	// System and Kind are those of the system the unit was registered with.
+	System string
+	Kind   string
+	// Locale marks a customary unit that shares names such as "gallon" with
+	// the unit of another locale; the Converter's locale picks between them.
+	Locale       Locale
 	ToBaseFunc   func(float64) float64
 	FromBaseFunc func(float64) float64
+
+	// Factor and Offset define the unit exactly as
+	// base = (value + Offset) * Factor. When ToBaseFunc and FromBaseFunc are
+	// nil they are derived from these at registration. Units with a nil
+	// Factor are not linear and cannot take part in exact arithmetic.
+	Factor *big.Rat
+	Offset *big.Rat
+	// Inexact marks a Factor that only approximates an irrational number,
+	// such as the π in a degree. Such units convert as precisely as their
+	// Factor allows, but never give an exact result.
+	Inexact bool
+
+	// Affine marks units on an offset scale, such as absolute temperatures
+	// and gauge pressures. Their values are points: they can be subtracted
+	// from one another and shifted by a difference, but not added together
+	// or scaled.
+	Affine bool
+	// Delta names the unit a difference of two Affine values is expressed in.
+	// Gauge units have none: every pressure unit but the gauges is absolute,
+	// so a gauge reading converts to psi or bar and back.
+	Delta string
+
+	// Prefixable units are registered with every SI prefix as well
+	// (Liters gives kL, mL, µL, ...), or with the prefixes Prefixes selects.
+	Prefixable bool
+	Prefixes   PrefixSet
+
+	// scale marks screen units measured at the Converter's display settings.
+	scale displayScale
+	// secondary marks a unit whose symbol is better known as another unit's.
+	// On its own a bare "F" is Fahrenheit; farads need an expression that
+	// calls for a capacitance, such as "10 F in µF".
+	secondary bool
+	// rate marks a currency registered from a snapshot of exchange rates,
+	// which the next snapshot replaces.
+	rate bool
+	// prefix names the prefix of a variant generated from a Prefixable unit,
+	// such as the "atto" of the attoelectronvolt.
+	prefix string
 }
 
+// UnitSystem groups the units of one dimension. Every factor is relative to
+// BaseUnit, which is the coherent SI unit of the dimension (m³, kg, K, ...),
+// so derived units multiply base values directly.
+//
+// Kind tells apart systems that measure different things in the same
+// dimension, such as torque and energy, which are both N·m. Units of
+// different kinds do not convert into one another, though either converts to
+// and from a unit without a kind. A registered name such as "N·m", "Nm" or
+// "N*m" is the newton meter of torque, while a product of other units, such
+// as "N m", "W·s" or "10 N * 5 m", has no kind.
 type UnitSystem struct {
-	Name     string
-	BaseUnit string
-	Units    map[string]Unit
+	Name      string
+	BaseUnit  string
+	Dimension Dimension
+	Kind      string
+	Units     map[string]Unit
 }
 
 func NewVolumeSystem() UnitSystem {
-	flOzToMl := 29.5735295625
-
 	return UnitSystem{
-		Name:     "Volume",
-		BaseUnit: "Milliliters",
+		Name:      "Volume",
+		BaseUnit:  "Cubic meters",
+		Dimension: DimVolume,
 		Units: map[string]Unit{
 			"Milliliters": {
-				Name:         "Milliliters",
-				Symbol:       "mL",
-				Aliases:      []string{"ml", "milliliter", "milliliters", "millilitre", "millilitres", "milli"},
-				ToBaseFunc:   func(val float64) float64 { return val },
-				FromBaseFunc: func(val float64) float64 { return val },
+				Name:    "Milliliters",
+				Symbol:  "mL",
+				Aliases: []string{"ml", "milliliter", "milliliters", "millilitre", "millilitres", "milli"},
+				Factor:  ratio("1e-6"),
 			},
 			"Liters": {
-				Name:         "Liters",
-				Symbol:       "L",
-				Aliases:      []string{"l", "liter", "liters", "litre", "litres"},
-				ToBaseFunc:   func(val float64) float64 { return val * 1000.0 },
-				FromBaseFunc: func(val float64) float64 { return val / 1000.0 },
+				Name:       "Liters",
+				Symbol:     "L",
+				Aliases:    []string{"l", "liter", "liters", "litre", "litres"},
+				Factor:     ratio("1e-3"),
+				Prefixable: true,
 			},
 			"Cubic meters": {
-				Name:         "Cubic meters",
-				Symbol:       "m³",
-				Aliases:      []string{"m3", "m^3", "cubicmeter", "cubicmeters"},
-				ToBaseFunc:   func(val float64) float64 { return val * 1000000.0 },
-				FromBaseFunc: func(val float64) float64 { return val / 1000000.0 },
+				Name:    "Cubic meters",
+				Symbol:  "m³",
+				Aliases: []string{"m3", "m^3", "cubicmeter", "cubicmeters"},
+				Factor:  ratio("1"),
 			},
 			"Cubic centimeters": {
-				Name:         "Cubic centimeters",
-				Symbol:       "cm³",
-				Aliases:      []string{"cm3", "cm^3", "cubiccentimeter", "cubiccentimeters", "cc"},
-				ToBaseFunc:   func(val float64) float64 { return val },
-				FromBaseFunc: func(val float64) float64 { return val },
+				Name:    "Cubic centimeters",
+				Symbol:  "cm³",
+				Aliases: []string{"cm3", "cm^3", "cubiccentimeter", "cubiccentimeters", "cc"},
+				Factor:  ratio("1e-6"),
 			},
 			"Fluid Ounce": {
-				Name:         "Fluid Ounce",
-				Symbol:       "fl oz",
-				Aliases:      []string{"floz", "fluidounce", "fluidounces", "oz"},
-				ToBaseFunc:   func(val float64) float64 { return val * flOzToMl },
-				FromBaseFunc: func(val float64) float64 { return val / flOzToMl },
+				Name:    "Fluid Ounce",
+				Symbol:  "fl oz",
+				Aliases: []string{"floz", "fluidounce", "fluidounces", "oz", "us fl oz", "us floz", "us fluid ounce", "us fluid ounces"},
+				Factor:  ratio("29.5735295625e-6"),
+				Locale:  LocaleUS,
+			},
+			"Imperial Fluid Ounce": {
+				Name:    "Imperial Fluid Ounce",
+				Symbol:  "imp fl oz",
+				Aliases: []string{"fl oz", "floz", "fluidounce", "fluidounces", "oz", "imp floz", "uk fl oz", "uk floz", "imperial fluid ounce", "imperial fluid ounces"},
+				Factor:  ratio("28.4130625e-6"),
+				Locale:  LocaleUK,
+			},
+			"Teaspoon": {
+				Name:    "Teaspoon",
+				Symbol:  "tsp",
+				Aliases: []string{"tsp", "teaspoon", "teaspoons"},
+				Factor:  ratio("4.92892159375e-6"),
+			},
+			"Tablespoon": {
+				Name:    "Tablespoon",
+				Symbol:  "tbsp",
+				Aliases: []string{"tbsp", "tablespoon", "tablespoons"},
+				Factor:  ratio("14.78676478125e-6"),
 			},
 			"Cup": {
-				Name:         "Cup",
-				Symbol:       "c",
-				Aliases:      []string{"cup", "cups"},
-				ToBaseFunc:   func(val float64) float64 { return val * flOzToMl * 8 },
-				FromBaseFunc: func(val float64) float64 { return val / (flOzToMl * 8) },
+				Name:    "Cup",
+				Symbol:  "c",
+				Aliases: []string{"cup", "cups"},
+				Factor:  ratio("236.5882365e-6"),
 			},
 			"Pint": {
-				Name:         "Pint",
-				Symbol:       "pt",
-				Aliases:      []string{"pint", "pints"},
-				ToBaseFunc:   func(val float64) float64 { return val * flOzToMl * 16 },
-				FromBaseFunc: func(val float64) float64 { return val / (flOzToMl * 16) },
+				Name:    "Pint",
+				Symbol:  "pt",
+				Aliases: []string{"pint", "pints", "us pt", "us pint", "us pints"},
+				Factor:  ratio("473.176473e-6"),
+				Locale:  LocaleUS,
+			},
+			"Imperial Pint": {
+				Name:    "Imperial Pint",
+				Symbol:  "imp pt",
+				Aliases: []string{"pt", "pint", "pints", "uk pt", "uk pint", "uk pints", "imperial pint", "imperial pints"},
+				Factor:  ratio("568.26125e-6"),
+				Locale:  LocaleUK,
 			},
 			"Quart": {
-				Name:         "Quart",
-				Symbol:       "qt",
-				Aliases:      []string{"quart", "quarts"},
-				ToBaseFunc:   func(val float64) float64 { return val * flOzToMl * 32 },
-				FromBaseFunc: func(val float64) float64 { return val / (flOzToMl * 32) },
+				Name:    "Quart",
+				Symbol:  "qt",
+				Aliases: []string{"quart", "quarts", "us qt", "us quart", "us quarts"},
+				Factor:  ratio("946.352946e-6"),
+				Locale:  LocaleUS,
+			},
+			"Imperial Quart": {
+				Name:    "Imperial Quart",
+				Symbol:  "imp qt",
+				Aliases: []string{"qt", "quart", "quarts", "uk qt", "uk quart", "uk quarts", "imperial quart", "imperial quarts"},
+				Factor:  ratio("1136.5225e-6"),
+				Locale:  LocaleUK,
 			},
 			"Gallon": {
-				Name:         "Gallon",
-				Symbol:       "gal",
-				Aliases:      []string{"gallon", "gallons"},
-				ToBaseFunc:   func(val float64) float64 { return val * flOzToMl * 128 },
-				FromBaseFunc: func(val float64) float64 { return val / (flOzToMl * 128) },
+				Name:    "Gallon",
+				Symbol:  "gal",
+				Aliases: []string{"gallon", "gallons", "us gal", "us gallon", "us gallons"},
+				Factor:  ratio("3785.411784e-6"),
+				Locale:  LocaleUS,
+			},
+			"Imperial Gallon": {
+				Name:    "Imperial Gallon",
+				Symbol:  "imp gal",
+				Aliases: []string{"gal", "gallon", "gallons", "uk gal", "uk gallon", "uk gallons", "imperial gallon", "imperial gallons"},
+				Factor:  ratio("4546.09e-6"),
+				Locale:  LocaleUK,
 			},
 			"Cubic feet": {
-				Name:         "Cubic feet",
-				Symbol:       "ft³",
-				Aliases:      []string{"ft3", "ft^3", "cubicfoot", "cubicfeet"},
-				ToBaseFunc:   func(val float64) float64 { return val * 28316.8 },
-				FromBaseFunc: func(val float64) float64 { return val / 28316.8 },
+				Name:    "Cubic feet",
+				Symbol:  "ft³",
+				Aliases: []string{"ft3", "ft^3", "cubicfoot", "cubicfeet"},
+				Factor:  ratio("0.028316846592"),
 			},
 			"Barrels": {
-				Name:         "Barrels",
-				Symbol:       "bbl",
-				Aliases:      []string{"barrel", "barrels"},
-				ToBaseFunc:   func(val float64) float64 { return val * 158987.3 },
-				FromBaseFunc: func(val float64) float64 { return val / 158987.3 },
+				Name:    "Barrels",
+				Symbol:  "bbl",
+				Aliases: []string{"barrel", "barrels"},
+				Factor:  ratio("0.158987294928"),
+			},
+		},
+	}
+}
+
+// NewTypographySystem returns the units of type and screen layout. They are
+// lengths, so they convert to and from meters, inches and the rest, and
+// share the base unit of the Length system. Pixels depend on the DPI and
+// ems on the font size; their factors here are those of CSS, 96 px to the
+// inch and 16 px text.
+func NewTypographySystem() UnitSystem {
+	perInch := func(n int64) *big.Rat {
+		return new(big.Rat).Quo(ratio("0.0254"), big.NewRat(n, 1))
+	}
+	return UnitSystem{
+		Name:      "Typography",
+		BaseUnit:  "Meters",
+		Dimension: DimLength,
+		Units: map[string]Unit{
+			"Pixels": {
+				Name:    "Pixels",
+				Symbol:  "px",
+				Aliases: []string{"pixel", "pixels"},
+				Factor:  perInch(96),
+				scale:   scalePixel,
+			},
+			"Points": {
+				Name:    "Points",
+				Symbol:  "pt",
+				Aliases: []string{"point", "points"},
+				Factor:  perInch(72),
+				// A bare "pt" is a pint, as it was before points
+				secondary: true,
+			},
+			"Picas": {
+				Name:    "Picas",
+				Symbol:  "pc",
+				Aliases: []string{"pica", "picas"},
+				Factor:  perInch(6),
+			},
+			"Ems": {
+				Name:    "Ems",
+				Symbol:  "em",
+				Aliases: []string{"ems"},
+				Factor:  perInch(6),
+				scale:   scaleFont,
+			},
+			// Without a parent element an em is the root font size
+			"Root ems": {
+				Name:    "Root ems",
+				Symbol:  "rem",
+				Aliases: []string{"rems", "root em"},
+				Factor:  perInch(6),
+				scale:   scaleFont,
+			},
+			// Android's density-independent pixel is one pixel at 160 dpi,
+			// whatever the screen
+			"Density-independent pixels": {
+				Name:    "Density-independent pixels",
+				Symbol:  "dp",
+				Aliases: []string{"dip", "dips"},
+				Factor:  perInch(160),
 			},
 		},
 	}
 }
 
+const (
+	// speedOfLight is in m/s, exact by the definition of the meter.
+	speedOfLight = "299792458"
+	// astronomicalUnit is in meters, exact by IAU 2012 Resolution B2.
+	astronomicalUnit = "149597870700"
+)
+
+// parsecs returns n parsecs in meters. IAU 2015 Resolution B2 defines the
+// parsec as exactly 648000/π astronomical units.
+func parsecs(n string) *big.Rat {
+	au := new(big.Rat).Mul(ratio(astronomicalUnit), ratio(n))
+	return new(big.Rat).Quo(au.Mul(au, big.NewRat(648000, 1)), piTimes("1"))
+}
+
 func NewLengthSystem() UnitSystem {
 	return UnitSystem{
-		Name:     "Length",
-		BaseUnit: "Meters",
+		Name:      "Length",
+		BaseUnit:  "Meters",
+		Dimension: DimLength,
 		Units: map[string]Unit{
 			"Meters": {
-				Name:         "Meters",
-				Symbol:       "m",
-				Aliases:      []string{"m", "meter", "meters", "metre", "metres"},
-				ToBaseFunc:   func(val float64) float64 { return val },
-				FromBaseFunc: func(val float64) float64 { return val },
-			},
-			"Kilometers": {
-				Name:         "Kilometers",
-				Symbol:       "km",
-				Aliases:      []string{"km", "kilometer", "kilometers", "kilometre", "kilometres"},
-				ToBaseFunc:   func(val float64) float64 { return val * 1000.0 },
-				FromBaseFunc: func(val float64) float64 { return val / 1000.0 },
-			},
-			"Centimeters": {
-				Name:         "Centimeters",
-				Symbol:       "cm",
-				Aliases:      []string{"cm", "centimeter", "centimeters", "centimetre", "centimetres"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.01 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.01 },
-			},
-			"Millimeters": {
-				Name:         "Millimeters",
-				Symbol:       "mm",
-				Aliases:      []string{"mm", "millimeter", "millimeters", "millimetre", "millimetres"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.001 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.001 },
+				Name:       "Meters",
+				Symbol:     "m",
+				Aliases:    []string{"m", "meter", "meters", "metre", "metres"},
+				Factor:     ratio("1"),
+				Prefixable: true,
 			},
 			"Inches": {
-				Name:         "Inches",
-				Symbol:       "in",
-				Aliases:      []string{"in", "inch", "inches"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.0254 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.0254 },
+				Name:    "Inches",
+				Symbol:  "in",
+				Aliases: []string{"in", "inch", "inches"},
+				Factor:  ratio("0.0254"),
 			},
 			"Feet": {
-				Name:         "Feet",
-				Symbol:       "ft",
-				Aliases:      []string{"ft", "foot", "feet"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.3048 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.3048 },
+				Name:    "Feet",
+				Symbol:  "ft",
+				Aliases: []string{"ft", "foot", "feet"},
+				Factor:  ratio("0.3048"),
 			},
 			"Yards": {
-				Name:         "Yards",
-				Symbol:       "yd",
-				Aliases:      []string{"yd", "yard", "yards"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.9144 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.9144 },
+				Name:    "Yards",
+				Symbol:  "yd",
+				Aliases: []string{"yd", "yard", "yards"},
+				Factor:  ratio("0.9144"),
 			},
 			"Miles": {
-				Name:         "Miles",
-				Symbol:       "mi",
-				Aliases:      []string{"mi", "mile", "miles"},
-				ToBaseFunc:   func(val float64) float64 { return val * 1609.34 },
-				FromBaseFunc: func(val float64) float64 { return val / 1609.34 },
+				Name:    "Miles",
+				Symbol:  "mi",
+				Aliases: []string{"mi", "mile", "miles"},
+				Factor:  ratio("1609.344"),
+			},
+			"Furlongs": {
+				Name:    "Furlongs",
+				Symbol:  "fur",
+				Aliases: []string{"furlong", "furlongs"},
+				Factor:  ratio("201.168"),
+			},
+			// Gunter's chain, 66 ft, used in surveying
+			"Chains": {
+				Name:    "Chains",
+				Symbol:  "ch",
+				Aliases: []string{"chain", "chains"},
+				Factor:  ratio("20.1168"),
+			},
+			// The US survey foot, 1200/3937 m, which older US land surveys
+			// and state plane coordinates are recorded in
+			"US survey feet": {
+				Name:    "US survey feet",
+				Symbol:  "ftUS",
+				Aliases: []string{"survey foot", "survey feet", "us survey foot", "us survey feet"},
+				Factor:  ratio("1200/3937"),
+			},
+			"Fathoms": {
+				Name:    "Fathoms",
+				Symbol:  "ftm",
+				Aliases: []string{"fathom", "fathoms"},
+				Factor:  ratio("1.8288"),
+			},
+			// "NM" is the nautical mile and "nm" the nanometer
+			"Nautical miles": {
+				Name:    "Nautical miles",
+				Symbol:  "nmi",
+				Aliases: []string{"NM", "nautical mile", "nautical miles"},
+				Factor:  ratio("1852"),
+			},
+			// The international cable, a tenth of a nautical mile
+			"Cables": {
+				Name:    "Cables",
+				Symbol:  "cable",
+				Aliases: []string{"cables", "cable length", "cable lengths"},
+				Factor:  ratio("185.2"),
+			},
+			"Astronomical units": {
+				Name:    "Astronomical units",
+				Symbol:  "au",
+				Aliases: []string{"AU", "astronomical unit", "astronomical units"},
+				Factor:  ratio(astronomicalUnit),
+			},
+			"Light-seconds": {
+				Name:    "Light-seconds",
+				Symbol:  "ls",
+				Aliases: []string{"light second", "light seconds", "lightsecond", "lightseconds"},
+				Factor:  ratio(speedOfLight),
+			},
+			"Light-minutes": {
+				Name:    "Light-minutes",
+				Symbol:  "lmin",
+				Aliases: []string{"light minute", "light minutes", "lightminute", "lightminutes"},
+				Factor:  new(big.Rat).Mul(ratio(speedOfLight), big.NewRat(60, 1)),
+			},
+			// The IAU light-year is the distance light travels in a Julian
+			// year of 365.25 days
+			"Light-years": {
+				Name:    "Light-years",
+				Symbol:  "ly",
+				Aliases: []string{"light year", "light years", "lightyear", "lightyears"},
+				Factor:  new(big.Rat).Mul(ratio(speedOfLight), ratio("31557600")),
+			},
+			// "pc" is the pica, so parsecs are spelled out
+			"Parsecs": {
+				Name:    "Parsecs",
+				Symbol:  "parsec",
+				Aliases: []string{"parsecs"},
+				Factor:  parsecs("1"),
+				Inexact: true,
+			},
+			"Kiloparsecs": {
+				Name:    "Kiloparsecs",
+				Symbol:  "kpc",
+				Aliases: []string{"kiloparsec", "kiloparsecs"},
+				Factor:  parsecs("1e3"),
+				Inexact: true,
+			},
+			"Megaparsecs": {
+				Name:    "Megaparsecs",
+				Symbol:  "Mpc",
+				Aliases: []string{"megaparsec", "megaparsecs"},
+				Factor:  parsecs("1e6"),
+				Inexact: true,
+			},
+			"Gigaparsecs": {
+				Name:    "Gigaparsecs",
+				Symbol:  "Gpc",
+				Aliases: []string{"gigaparsec", "gigaparsecs"},
+				Factor:  parsecs("1e9"),
+				Inexact: true,
 			},
 		},
 	}
@@ -171,43 +413,49 @@ func NewLengthSystem() UnitSystem {
 
 func NewWeightSystem() UnitSystem {
 	return UnitSystem{
-		Name:     "Weight",
-		BaseUnit: "Grams",
+		Name:      "Weight",
+		BaseUnit:  "Kilograms",
+		Dimension: DimMass,
 		Units: map[string]Unit{
 			"Grams": {
-				Name:         "Grams",
-				Symbol:       "g",
-				Aliases:      []string{"g", "gram", "grams"},
-				ToBaseFunc:   func(val float64) float64 { return val },
-				FromBaseFunc: func(val float64) float64 { return val },
-			},
-			"Kilograms": {
-				Name:         "Kilograms",
-				Symbol:       "kg",
-				Aliases:      []string{"kg", "kilogram", "kilograms"},
-				ToBaseFunc:   func(val float64) float64 { return val * 1000.0 },
-				FromBaseFunc: func(val float64) float64 { return val / 1000.0 },
-			},
-			"Milligrams": {
-				Name:         "Milligrams",
-				Symbol:       "mg",
-				Aliases:      []string{"mg", "milligram", "milligrams"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.001 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.001 },
+				Name:       "Grams",
+				Symbol:     "g",
+				Aliases:    []string{"g", "gram", "grams"},
+				Factor:     ratio("1e-3"),
+				Prefixable: true,
 			},
 			"Pounds": {
-				Name:         "Pounds",
-				Symbol:       "lb",
-				Aliases:      []string{"lb", "lbs", "pound", "pounds"},
-				ToBaseFunc:   func(val float64) float64 { return val * 453.592 },
-				FromBaseFunc: func(val float64) float64 { return val / 453.592 },
+				Name:    "Pounds",
+				Symbol:  "lb",
+				Aliases: []string{"lb", "lbs", "pound", "pounds"},
+				Factor:  ratio("0.45359237"),
 			},
 			"Ounces": {
-				Name:         "Ounces",
-				Symbol:       "oz",
-				Aliases:      []string{"ounce", "ounces"},
-				ToBaseFunc:   func(val float64) float64 { return val * 28.3495 },
-				FromBaseFunc: func(val float64) float64 { return val / 28.3495 },
+				Name:    "Ounces",
+				Symbol:  "oz",
+				Aliases: []string{"ounce", "ounces"},
+				Factor:  ratio("0.028349523125"),
+			},
+			"Tonnes": {
+				Name:    "Tonnes",
+				Symbol:  "t",
+				Aliases: []string{"tonne", "tonnes", "metric ton", "metric tons"},
+				Factor:  ratio("1000"),
+			},
+			// A ton is 2000 lb in the US and 2240 lb in the UK
+			"Short tons": {
+				Name:    "Short tons",
+				Symbol:  "sh tn",
+				Aliases: []string{"ton", "tons", "short ton", "us ton", "us tons"},
+				Factor:  ratio("907.18474"),
+				Locale:  LocaleUS,
+			},
+			"Long tons": {
+				Name:    "Long tons",
+				Symbol:  "long tn",
+				Aliases: []string{"ton", "tons", "long ton", "uk ton", "uk tons", "imperial ton", "imperial tons"},
+				Factor:  ratio("1016.0469088"),
+				Locale:  LocaleUK,
 			},
 		},
 	}
@@ -215,29 +463,53 @@ func NewWeightSystem() UnitSystem {
 
 func NewTemperatureSystem() UnitSystem {
 	return UnitSystem{
-		Name:     "Temperature",
-		BaseUnit: "Celsius",
+		Name:      "Temperature",
+		BaseUnit:  "Kelvin",
+		Dimension: DimTemperature,
 		Units: map[string]Unit{
 			"Celsius": {
-				Name:         "Celsius",
-				Symbol:       "°C",
-				Aliases:      []string{"c", "celsius"},
-				ToBaseFunc:   func(val float64) float64 { return val },
-				FromBaseFunc: func(val float64) float64 { return val },
+				Name:    "Celsius",
+				Symbol:  "°C",
+				Aliases: []string{"c", "C", "celsius"},
+				Factor:  ratio("1"),
+				Offset:  ratio("273.15"),
+				Affine:  true,
+				Delta:   "Delta Celsius",
 			},
 			"Fahrenheit": {
-				Name:         "Fahrenheit",
-				Symbol:       "°F",
-				Aliases:      []string{"f", "fahrenheit"},
-				ToBaseFunc:   func(val float64) float64 { return (val - 32) * 5 / 9 },
-				FromBaseFunc: func(val float64) float64 { return (val * 9 / 5) + 32 },
+				Name:    "Fahrenheit",
+				Symbol:  "°F",
+				Aliases: []string{"f", "F", "fahrenheit"},
+				Factor:  ratio("5/9"),
+				Offset:  ratio("459.67"),
+				Affine:  true,
+				Delta:   "Delta Fahrenheit",
 			},
 			"Kelvin": {
-				Name:         "Kelvin",
-				Symbol:       "K",
-				Aliases:      []string{"k", "kelvin"},
-				ToBaseFunc:   func(val float64) float64 { return val - 273.15 },
-				FromBaseFunc: func(val float64) float64 { return val + 273.15 },
+				Name:    "Kelvin",
+				Symbol:  "K",
+				Aliases: []string{"k", "kelvin"},
+				Factor:  ratio("1"),
+				Affine:  true,
+				Delta:   "Delta Kelvin",
+			},
+			"Delta Celsius": {
+				Name:    "Delta Celsius",
+				Symbol:  "Δ°C",
+				Aliases: []string{"Δc", "deltac", "deltacelsius"},
+				Factor:  ratio("1"),
+			},
+			"Delta Fahrenheit": {
+				Name:    "Delta Fahrenheit",
+				Symbol:  "Δ°F",
+				Aliases: []string{"Δf", "deltaf", "deltafahrenheit"},
+				Factor:  ratio("5/9"),
+			},
+			"Delta Kelvin": {
+				Name:    "Delta Kelvin",
+				Symbol:  "ΔK",
+				Aliases: []string{"Δk", "deltak", "deltakelvin"},
+				Factor:  ratio("1"),
 			},
 		},
 	}
@@ -245,64 +517,57 @@ func NewTemperatureSystem() UnitSystem {
 
 func NewAreaSystem() UnitSystem {
 	return UnitSystem{
-		Name:     "Area",
-		BaseUnit: "Square Meters",
+		Name:      "Area",
+		BaseUnit:  "Square Meters",
+		Dimension: DimArea,
 		Units: map[string]Unit{
 			"Square Meters": {
-				Name:         "Square Meters",
-				Symbol:       "m²",
-				Aliases:      []string{"m2", "sqm", "squaremeter", "squaremeters"},
-				ToBaseFunc:   func(val float64) float64 { return val },
-				FromBaseFunc: func(val float64) float64 { return val },
+				Name:    "Square Meters",
+				Symbol:  "m²",
+				Aliases: []string{"m2", "sqm", "squaremeter", "squaremeters"},
+				Factor:  ratio("1"),
 			},
 			"Square Kilometers": {
-				Name:         "Square Kilometers",
-				Symbol:       "km²",
-				Aliases:      []string{"km2", "sqkm", "squarekilometer", "squarekilometers"},
-				ToBaseFunc:   func(val float64) float64 { return val * 1000000.0 },
-				FromBaseFunc: func(val float64) float64 { return val / 1000000.0 },
+				Name:    "Square Kilometers",
+				Symbol:  "km²",
+				Aliases: []string{"km2", "sqkm", "squarekilometer", "squarekilometers"},
+				Factor:  ratio("1000000"),
 			},
 			"Hectares": {
-				Name:         "Hectares",
-				Symbol:       "ha",
-				Aliases:      []string{"ha", "hectare", "hectares"},
-				ToBaseFunc:   func(val float64) float64 { return val * 10000.0 },
-				FromBaseFunc: func(val float64) float64 { return val / 10000.0 },
+				Name:    "Hectares",
+				Symbol:  "ha",
+				Aliases: []string{"ha", "hectare", "hectares"},
+				Factor:  ratio("10000"),
 			},
 			"Square Miles": {
-				Name:         "Square Miles",
-				Symbol:       "mi²",
-				Aliases:      []string{"mi2", "sqmi", "squaremile", "squaremiles"},
-				ToBaseFunc:   func(val float64) float64 { return val * 2589988.11 },
-				FromBaseFunc: func(val float64) float64 { return val / 2589988.11 },
+				Name:    "Square Miles",
+				Symbol:  "mi²",
+				Aliases: []string{"mi2", "sqmi", "squaremile", "squaremiles"},
+				Factor:  ratio("2589988.110336"),
 			},
 			"Acres": {
-				Name:         "Acres",
-				Symbol:       "ac",
-				Aliases:      []string{"ac", "acre", "acres"},
-				ToBaseFunc:   func(val float64) float64 { return val * 4046.86 },
-				FromBaseFunc: func(val float64) float64 { return val / 4046.86 },
+				Name:    "Acres",
+				Symbol:  "ac",
+				Aliases: []string{"ac", "acre", "acres"},
+				Factor:  ratio("4046.8564224"),
 			},
 			"Square Yards": {
-				Name:         "Square Yards",
-				Symbol:       "yd²",
-				Aliases:      []string{"yd2", "sqyd", "squareyard", "squareyards"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.836127 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.836127 },
+				Name:    "Square Yards",
+				Symbol:  "yd²",
+				Aliases: []string{"yd2", "sqyd", "squareyard", "squareyards"},
+				Factor:  ratio("0.83612736"),
 			},
 			"Square Feet": {
-				Name:         "Square Feet",
-				Symbol:       "ft²",
-				Aliases:      []string{"ft2", "sqft", "squarefoot", "squarefeet"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.092903 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.092903 },
+				Name:    "Square Feet",
+				Symbol:  "ft²",
+				Aliases: []string{"ft2", "sqft", "squarefoot", "squarefeet"},
+				Factor:  ratio("0.09290304"),
 			},
 			"Square Inches": {
-				Name:         "Square Inches",
-				Symbol:       "in²",
-				Aliases:      []string{"in2", "sqin", "squareinch", "squareinches"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.00064516 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.00064516 },
+				Name:    "Square Inches",
+				Symbol:  "in²",
+				Aliases: []string{"in2", "sqin", "squareinch", "squareinches"},
+				Factor:  ratio("0.00064516"),
 			},
 		},
 	}
@@ -310,43 +575,39 @@ func NewAreaSystem() UnitSystem {
 
 func NewSpeedSystem() UnitSystem {
 	return UnitSystem{
-		Name:     "Speed",
-		BaseUnit: "Meters per Second",
+		Name:      "Speed",
+		BaseUnit:  "Meters per Second",
+		Dimension: DimSpeed,
 		Units: map[string]Unit{
 			"Meters per Second": {
-				Name:         "Meters per Second",
-				Symbol:       "m/s",
-				Aliases:      []string{"mps", "meterspersecond"},
-				ToBaseFunc:   func(val float64) float64 { return val },
-				FromBaseFunc: func(val float64) float64 { return val },
+				Name:    "Meters per Second",
+				Symbol:  "m/s",
+				Aliases: []string{"mps", "meterspersecond"},
+				Factor:  ratio("1"),
 			},
 			"Kilometers per Hour": {
-				Name:         "Kilometers per Hour",
-				Symbol:       "km/h",
-				Aliases:      []string{"kph", "kmh", "kilometersperhour"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.277778 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.277778 },
+				Name:    "Kilometers per Hour",
+				Symbol:  "km/h",
+				Aliases: []string{"kph", "kmh", "kilometersperhour"},
+				Factor:  ratio("5/18"),
 			},
 			"Miles per Hour": {
-				Name:         "Miles per Hour",
-				Symbol:       "mph",
-				Aliases:      []string{"mph", "milesperhour"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.44704 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.44704 },
+				Name:    "Miles per Hour",
+				Symbol:  "mph",
+				Aliases: []string{"mph", "milesperhour"},
+				Factor:  ratio("0.44704"),
 			},
 			"Knots": {
-				Name:         "Knots",
-				Symbol:       "kt",
-				Aliases:      []string{"kt", "knots"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.514444 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.514444 },
+				Name:    "Knots",
+				Symbol:  "kt",
+				Aliases: []string{"kt", "knots"},
+				Factor:  ratio("463/900"),
 			},
 			"Feet per Second": {
-				Name:         "Feet per Second",
-				Symbol:       "ft/s",
-				Aliases:      []string{"fps", "feetpersecond"},
-				ToBaseFunc:   func(val float64) float64 { return val * 0.3048 },
-				FromBaseFunc: func(val float64) float64 { return val / 0.3048 },
+				Name:    "Feet per Second",
+				Symbol:  "ft/s",
+				Aliases: []string{"fps", "feetpersecond"},
+				Factor:  ratio("0.3048"),
 			},
 		},
 	}
@@ -354,110 +615,861 @@ func NewSpeedSystem() UnitSystem {
 
 func NewTimeSystem() UnitSystem {
 	return UnitSystem{
-		Name:     "Time",
-		BaseUnit: "Seconds",
+		Name:      "Time",
+		BaseUnit:  "Seconds",
+		Dimension: DimTime,
 		Units: map[string]Unit{
-			"Nanoseconds": {
-				Name:         "Nanoseconds",
-				Symbol:       "ns",
-				Aliases:      []string{"ns", "nanosecond", "nanoseconds"},
-				ToBaseFunc:   func(val float64) float64 { return val / 1e9 },
-				FromBaseFunc: func(val float64) float64 { return val * 1e9 },
-			},
-			"Microseconds": {
-				Name:         "Microseconds",
-				Symbol:       "µs",
-				Aliases:      []string{"us", "microsecond", "microseconds"},
-				ToBaseFunc:   func(val float64) float64 { return val / 1e6 },
-				FromBaseFunc: func(val float64) float64 { return val * 1e6 },
-			},
-			"Milliseconds": {
-				Name:         "Milliseconds",
-				Symbol:       "ms",
-				Aliases:      []string{"ms", "millisecond", "milliseconds"},
-				ToBaseFunc:   func(val float64) float64 { return val / 1e3 },
-				FromBaseFunc: func(val float64) float64 { return val * 1e3 },
-			},
 			"Seconds": {
-				Name:         "Seconds",
-				Symbol:       "s",
-				Aliases:      []string{"s", "sec", "second", "seconds"},
-				ToBaseFunc:   func(val float64) float64 { return val },
-				FromBaseFunc: func(val float64) float64 { return val },
+				Name:       "Seconds",
+				Symbol:     "s",
+				Aliases:    []string{"s", "sec", "second", "seconds"},
+				Factor:     ratio("1"),
+				Prefixable: true,
 			},
 			"Minutes": {
-				Name:         "Minutes",
-				Symbol:       "min",
-				Aliases:      []string{"min", "minute", "minutes"},
-				ToBaseFunc:   func(val float64) float64 { return val * 60 },
-				FromBaseFunc: func(val float64) float64 { return val / 60 },
+				Name:    "Minutes",
+				Symbol:  "min",
+				Aliases: []string{"min", "minute", "minutes"},
+				Factor:  ratio("60"),
 			},
 			"Hours": {
-				Name:         "Hours",
-				Symbol:       "hr",
-				Aliases:      []string{"h", "hr", "hour", "hours"},
-				ToBaseFunc:   func(val float64) float64 { return val * 3600 },
-				FromBaseFunc: func(val float64) float64 { return val / 3600 },
+				Name:    "Hours",
+				Symbol:  "hr",
+				Aliases: []string{"h", "hr", "hour", "hours"},
+				Factor:  ratio("3600"),
 			},
 			"Days": {
-				Name:         "Days",
-				Symbol:       "d",
-				Aliases:      []string{"d", "day", "days"},
-				ToBaseFunc:   func(val float64) float64 { return val * 86400 },
-				FromBaseFunc: func(val float64) float64 { return val / 86400 },
+				Name:    "Days",
+				Symbol:  "d",
+				Aliases: []string{"d", "day", "days"},
+				Factor:  ratio("86400"),
 			},
 			"Weeks": {
-				Name:         "Weeks",
-				Symbol:       "wk",
-				Aliases:      []string{"wk", "week", "weeks"},
-				ToBaseFunc:   func(val float64) float64 { return val * 604800 },
-				FromBaseFunc: func(val float64) float64 { return val / 604800 },
+				Name:    "Weeks",
+				Symbol:  "wk",
+				Aliases: []string{"wk", "week", "weeks"},
+				Factor:  ratio("604800"),
 			},
 			"Months": {
-				Name:         "Months",
-				Symbol:       "mo",
-				Aliases:      []string{"mo", "month", "months"},
-				ToBaseFunc:   func(val float64) float64 { return val * 2629728 },
-				FromBaseFunc: func(val float64) float64 { return val / 2629728 },
+				Name:    "Months",
+				Symbol:  "mo",
+				Aliases: []string{"mo", "month", "months"},
+				Factor:  ratio("2629728"),
 			},
 			"Years": {
-				Name:         "Years",
-				Symbol:       "yr",
-				Aliases:      []string{"y", "yr", "year", "years"},
-				ToBaseFunc:   func(val float64) float64 { return val * 31557600 },
-				FromBaseFunc: func(val float64) float64 { return val / 31557600 },
+				Name:    "Years",
+				Symbol:  "yr",
+				Aliases: []string{"y", "yr", "year", "years"},
+				Factor:  ratio("31557600"),
 			},
 			"Decades": {
-				Name:         "Decades",
-				Symbol:       "dec",
-				Aliases:      []string{"dec", "decade", "decades"},
-				ToBaseFunc:   func(val float64) float64 { return val * 315576000 },
-				FromBaseFunc: func(val float64) float64 { return val / 315576000 },
+				Name:    "Decades",
+				Symbol:  "dec",
+				Aliases: []string{"dec", "decade", "decades"},
+				Factor:  ratio("315576000"),
 			},
 		},
 	}
 }
 
-func MustRegisterSystems() map[string]Unit {
-	systems := []UnitSystem{
-		NewVolumeSystem(),
-		NewLengthSystem(),
-		NewWeightSystem(),
-		NewTemperatureSystem(),
-		NewAreaSystem(),
-		NewSpeedSystem(),
-		NewTimeSystem(),
+func (u Unit) keys() []string {
+	return append([]string{u.Name, u.Symbol}, u.Aliases...)
+}
+
+// hasKey reports whether s is exactly, case included, one of the unit's
+// names, symbols or aliases.
+func (u Unit) hasKey(s string) bool {
+	for _, key := range u.keys() {
+		if key == s {
+			return true
+		}
 	}
+	return false
+}
 
-	unitMap := make(map[string]Unit)
-	for _, system := range systems {
-		for _, unit := range system.Units {
-			for _, alias := range unit.Aliases {
-				unitMap[strings.ToLower(alias)] = unit
-			}
-			unitMap[strings.ToLower(unit.Name)] = unit
-			unitMap[strings.ToLower(unit.Symbol)] = unit
+func (u Unit) sharesKey(o Unit) bool {
+	for _, key := range o.keys() {
+		if u.hasKey(key) {
+			return true
 		}
 	}
-	return unitMap
+	return false
+}
+
+// ratio parses an exact decimal or fraction such as "0.3048" or "5/9".
+func ratio(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic(fmt.Sprintf("invalid ratio: '%s'", s))
+	}
+	return r
+}
+
+// isOne reports whether a factor is exactly 1.
+func isOne(r *big.Rat) bool {
+	return r != nil && r.Cmp(big.NewRat(1, 1)) == 0
+}
+
+// piRatio is π to 50 decimal places. Units defined through it are Inexact:
+// their conversions are accurate far beyond a float64, but not exact.
+var piRatio = ratio("3.14159265358979323846264338327950288419716939937510")
+
+// piTimes returns π times an exact decimal or fraction.
+func piTimes(s string) *big.Rat {
+	return new(big.Rat).Mul(piRatio, ratio(s))
+}
+
+// withFactorFuncs fills in missing conversion functions from Factor and Offset.
+func (u Unit) withFactorFuncs() Unit {
+	if u.Factor == nil || (u.ToBaseFunc != nil && u.FromBaseFunc != nil) {
+		return u
+	}
+	factor, _ := u.Factor.Float64()
+	offset := 0.0
+	if u.Offset != nil {
+		offset, _ = u.Offset.Float64()
+	}
+	u.ToBaseFunc = func(val float64) float64 { return (val + offset) * factor }
+	u.FromBaseFunc = func(val float64) float64 { return val/factor - offset }
+	return u
+}
+
+// toBaseExact is the exact counterpart of ToBaseFunc; ok is false for units
+// without a Factor.
+func (u Unit) toBaseExact(val *big.Rat) (*big.Rat, bool) {
+	if u.Factor == nil {
+		return nil, false
+	}
+	r := new(big.Rat).Set(val)
+	if u.Offset != nil {
+		r.Add(r, u.Offset)
+	}
+	return r.Mul(r, u.Factor), true
+}
+
+// fromBaseExact is the exact counterpart of FromBaseFunc.
+func (u Unit) fromBaseExact(val *big.Rat) (*big.Rat, bool) {
+	if u.Factor == nil || u.Factor.Sign() == 0 {
+		return nil, false
+	}
+	r := new(big.Rat).Quo(val, u.Factor)
+	if u.Offset != nil {
+		r.Sub(r, u.Offset)
+	}
+	return r, true
+}
+
+func NewEnergySystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Energy",
+		BaseUnit:  "Joules",
+		Dimension: DimEnergy,
+		Kind:      "energy",
+		Units: map[string]Unit{
+			"Joules": {
+				Name:       "Joules",
+				Symbol:     "J",
+				Aliases:    []string{"j", "joule", "joules"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+			},
+			// The small (gram) calorie of chemistry; the thermochemical
+			// definition is exact.
+			"Gram calories": {
+				Name:    "Gram calories",
+				Symbol:  "cal",
+				Aliases: []string{"cal", "calorie", "calories", "gramcalorie", "gramcalories", "smallcalorie", "smallcalories"},
+				Factor:  ratio("4.184"),
+			},
+			// The food Calorie, capitalized, is a kilocalorie.
+			"Kilocalories": {
+				Name:    "Kilocalories",
+				Symbol:  "kcal",
+				Aliases: []string{"kcal", "kilocalorie", "kilocalories", "Cal", "Calorie", "Calories", "food calorie", "food calories", "foodcalorie", "foodcalories"},
+				Factor:  ratio("4184"),
+			},
+			"Watt hours": {
+				Name:       "Watt hours",
+				Symbol:     "Wh",
+				Aliases:    []string{"wh", "watthour", "watthours", "watt hour", "watt hours"},
+				Factor:     ratio("3600"),
+				Prefixable: true,
+			},
+			"British thermal units": {
+				Name:    "British thermal units",
+				Symbol:  "BTU",
+				Aliases: []string{"btu", "btus", "britishthermalunit", "britishthermalunits"},
+				Factor:  ratio("1055.05585262"),
+			},
+			"Electronvolts": {
+				Name:       "Electronvolts",
+				Symbol:     "eV",
+				Aliases:    []string{"ev", "electronvolt", "electronvolts"},
+				Factor:     ratio("1.602176634e-19"),
+				Prefixable: true,
+			},
+			// A therm is 100,000 BTU.
+			"Therms": {
+				Name:    "Therms",
+				Symbol:  "thm",
+				Aliases: []string{"thm", "therm", "therms"},
+				Factor:  ratio("105505585.262"),
+			},
+		},
+	}
+}
+
+func NewPowerSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Power",
+		BaseUnit:  "Watts",
+		Dimension: DimPower,
+		Units: map[string]Unit{
+			"Watts": {
+				Name:       "Watts",
+				Symbol:     "W",
+				Aliases:    []string{"w", "watt", "watts"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+			},
+			// Mechanical horsepower is 550 ft·lbf/s.
+			"Horsepower": {
+				Name:    "Horsepower",
+				Symbol:  "hp",
+				Aliases: []string{"hp", "bhp", "horsepower", "mechanicalhorsepower"},
+				Factor:  ratio("37284993579113511/50000000000000"),
+			},
+			// Metric horsepower (Pferdestärke) is 75 kgf·m/s.
+			"Metric horsepower": {
+				Name:    "Metric horsepower",
+				Symbol:  "PS",
+				Aliases: []string{"PS", "cv", "metrichorsepower", "metric horsepower", "pferdestärke"},
+				Factor:  ratio("588399/800"),
+			},
+			"BTU per hour": {
+				Name:    "BTU per hour",
+				Symbol:  "BTU/h",
+				Aliases: []string{"btu/h", "btu/hr", "btuh", "btuperhour"},
+				Factor:  ratio("52752792631/180000000000"),
+			},
+			// A ton of refrigeration melts a short ton of ice a day,
+			// 12,000 BTU/h.
+			"Tons of refrigeration": {
+				Name:    "Tons of refrigeration",
+				Symbol:  "TR",
+				Aliases: []string{"TR", "RT", "ton of refrigeration", "tons of refrigeration", "refrigeration ton", "refrigeration tons"},
+				Factor:  ratio("52752792631/15000000"),
+			},
+		},
+	}
+}
+
+// NewPressureSystem returns absolute pressure units along with the gauge
+// units psig and barg, which read zero at one standard atmosphere. Their
+// Offset is that atmosphere, and like absolute temperatures they are Affine:
+// two gauge readings cannot be added or scaled, a gauge reading minus another
+// is a pressure difference, and a reading plus a difference is a reading.
+func NewPressureSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Pressure",
+		BaseUnit:  "Pascals",
+		Dimension: DimPressure,
+		Units: map[string]Unit{
+			"Pascals": {
+				Name:       "Pascals",
+				Symbol:     "Pa",
+				Aliases:    []string{"pa", "pascal", "pascals"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+			},
+			"Bars": {
+				Name:       "Bars",
+				Symbol:     "bar",
+				Aliases:    []string{"bar", "bars"},
+				Factor:     ratio("100000"),
+				Prefixable: true,
+			},
+			"Millibars": {
+				Name:    "Millibars",
+				Symbol:  "mbar",
+				Aliases: []string{"mbar", "millibar", "millibars"},
+				Factor:  ratio("100"),
+			},
+			"Pounds per square inch": {
+				Name:    "Pounds per square inch",
+				Symbol:  "psi",
+				Aliases: []string{"psi", "psia", "poundspersquareinch"},
+				Factor:  ratio("8896443230521/1290320000"),
+			},
+			"Pounds per square inch gauge": {
+				Name:    "Pounds per square inch gauge",
+				Symbol:  "psig",
+				Aliases: []string{"psig"},
+				Factor:  ratio("8896443230521/1290320000"),
+				Offset:  ratio("18677382000000/1270920461503"),
+				Affine:  true,
+			},
+			"Bars gauge": {
+				Name:    "Bars gauge",
+				Symbol:  "barg",
+				Aliases: []string{"barg"},
+				Factor:  ratio("100000"),
+				Offset:  ratio("1.01325"),
+				Affine:  true,
+			},
+			"Atmospheres": {
+				Name:    "Atmospheres",
+				Symbol:  "atm",
+				Aliases: []string{"atm", "atmosphere", "atmospheres"},
+				Factor:  ratio("101325"),
+			},
+			"Millimeters of mercury": {
+				Name:    "Millimeters of mercury",
+				Symbol:  "mmHg",
+				Aliases: []string{"mmhg", "millimetersofmercury"},
+				Factor:  ratio("133.322387415"),
+			},
+			"Inches of mercury": {
+				Name:    "Inches of mercury",
+				Symbol:  "inHg",
+				Aliases: []string{"inhg", "inchesofmercury"},
+				Factor:  ratio("3386.388640341"),
+			},
+			"Torr": {
+				Name:    "Torr",
+				Symbol:  "Torr",
+				Aliases: []string{"torr"},
+				Factor:  ratio("101325/760"),
+			},
+		},
+	}
+}
+
+// NewDataSystem returns units of digital information. Lowercase "b" is a bit
+// and uppercase "B" a byte; decimal prefixes (kB, MB) are powers of 1000 and
+// binary ones (KiB, MiB) powers of 1024. "KB", with a capital K that is no SI
+// prefix, follows the JEDEC convention of 1024 bytes.
+func NewDataSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Data",
+		BaseUnit:  "Bits",
+		Dimension: DimInformation,
+		Units: map[string]Unit{
+			"Bits": {
+				Name:       "Bits",
+				Symbol:     "bit",
+				Aliases:    []string{"b", "bit", "bits"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+				Prefixes:   DataPrefixes,
+			},
+			"Bytes": {
+				Name:       "Bytes",
+				Symbol:     "B",
+				Aliases:    []string{"B", "byte", "bytes", "octet", "octets"},
+				Factor:     ratio("8"),
+				Prefixable: true,
+				Prefixes:   DataPrefixes,
+			},
+			"Kibibytes": {
+				Name:    "Kibibytes",
+				Symbol:  "KiB",
+				Aliases: []string{"KiB", "KB", "kibibyte", "kibibytes"},
+				Factor:  ratio("8192"),
+			},
+		},
+	}
+}
+
+// NewDataRateSystem returns bit and byte rates. "Mbps" is megabits per
+// second; bytes are only written out ("MB/s"). Other rates are compounds of
+// a data unit and a time unit, such as "GiB/h".
+func NewDataRateSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Data rate",
+		BaseUnit:  "Bits per second",
+		Dimension: DimDataRate,
+		Units: map[string]Unit{
+			"Bits per second": {
+				Name:       "Bits per second",
+				Symbol:     "bit/s",
+				Aliases:    []string{"bps", "b/s", "bitspersecond"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+				Prefixes:   DataPrefixes,
+			},
+			"Bytes per second": {
+				Name:       "Bytes per second",
+				Symbol:     "B/s",
+				Aliases:    []string{"bytespersecond"},
+				Factor:     ratio("8"),
+				Prefixable: true,
+				Prefixes:   DataPrefixes,
+			},
+		},
+	}
+}
+
+func NewForceSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Force",
+		BaseUnit:  "Newtons",
+		Dimension: DimForce,
+		Units: map[string]Unit{
+			"Newtons": {
+				Name:       "Newtons",
+				Symbol:     "N",
+				Aliases:    []string{"N", "newton", "newtons"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+			},
+			"Pounds force": {
+				Name:    "Pounds force",
+				Symbol:  "lbf",
+				Aliases: []string{"lbf", "poundforce", "poundsforce", "pound force", "pounds force"},
+				Factor:  ratio("4.4482216152605"),
+			},
+			"Kilograms force": {
+				Name:    "Kilograms force",
+				Symbol:  "kgf",
+				Aliases: []string{"kgf", "kp", "kilogramforce", "kilogramsforce", "kilogram force", "kilograms force", "kilopond"},
+				Factor:  ratio("9.80665"),
+			},
+			"Dynes": {
+				Name:    "Dynes",
+				Symbol:  "dyn",
+				Aliases: []string{"dyn", "dyne", "dynes"},
+				Factor:  ratio("1e-5"),
+			},
+		},
+	}
+}
+
+// NewTorqueSystem returns units of torque. Torque has the dimension of
+// energy but is a different kind of quantity, so "50 N·m in J" is an error.
+func NewTorqueSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Torque",
+		BaseUnit:  "Newton meters",
+		Dimension: DimEnergy,
+		Kind:      "torque",
+		Units: map[string]Unit{
+			"Newton meters": {
+				Name:       "Newton meters",
+				Symbol:     "N·m",
+				Aliases:    []string{"Nm", "N*m", "newtonmeter", "newtonmeters", "newton meter", "newton meters"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+			},
+			"Pound-force feet": {
+				Name:    "Pound-force feet",
+				Symbol:  "lbf·ft",
+				Aliases: []string{"lbf*ft", "lbfft", "lb·ft", "lb*ft", "lbft", "poundfoot", "poundfeet", "pound foot", "pound feet"},
+				Factor:  ratio("1.3558179483314004"),
+			},
+			"Pound-force inches": {
+				Name:    "Pound-force inches",
+				Symbol:  "lbf·in",
+				Aliases: []string{"lbf*in", "lbfin", "lb·in", "lb*in", "poundinch", "poundinches", "pound inch", "pound inches"},
+				Factor:  ratio("0.1129848290276167"),
+			},
+			"Kilogram-force meters": {
+				Name:    "Kilogram-force meters",
+				Symbol:  "kgf·m",
+				Aliases: []string{"kgf*m", "kgfm"},
+				Factor:  ratio("9.80665"),
+			},
+		},
+	}
+}
+
+// NewAngleSystem returns units of plane angle. As in SI, angles are
+// dimensionless and the radian is their coherent unit.
+func NewAngleSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Angle",
+		BaseUnit:  "Radians",
+		Dimension: Dimensionless,
+		Kind:      "angle",
+		Units: map[string]Unit{
+			"Radians": {
+				Name:    "Radians",
+				Symbol:  "rad",
+				Aliases: []string{"rad", "rads", "radian", "radians"},
+				Factor:  ratio("1"),
+			},
+			"Milliradians": {
+				Name:    "Milliradians",
+				Symbol:  "mrad",
+				Aliases: []string{"mrad", "milliradian", "milliradians"},
+				Factor:  ratio("1/1000"),
+			},
+			"Degrees": {
+				Name:    "Degrees",
+				Symbol:  "°",
+				Aliases: []string{"°", "deg", "degs", "degree", "degrees"},
+				Factor:  piTimes("1/180"),
+				Inexact: true,
+			},
+			"Arcminutes": {
+				Name:    "Arcminutes",
+				Symbol:  "arcmin",
+				Aliases: []string{"′", "arcmin", "arcmins", "arcminute", "arcminutes", "MOA"},
+				Factor:  piTimes("1/10800"),
+				Inexact: true,
+			},
+			"Arcseconds": {
+				Name:    "Arcseconds",
+				Symbol:  "arcsec",
+				Aliases: []string{"″", "arcsec", "arcsecs", "arcsecond", "arcseconds"},
+				Factor:  piTimes("1/648000"),
+				Inexact: true,
+			},
+			"Gradians": {
+				Name:    "Gradians",
+				Symbol:  "gon",
+				Aliases: []string{"gon", "gons", "grad", "grads", "gradian", "gradians"},
+				Factor:  piTimes("1/200"),
+				Inexact: true,
+			},
+			"Turns": {
+				Name:    "Turns",
+				Symbol:  "turn",
+				Aliases: []string{"turn", "turns", "rev", "revs", "revolution", "revolutions"},
+				Factor:  piTimes("2"),
+				Inexact: true,
+			},
+		},
+	}
+}
+
+// NewFrequencySystem returns units of frequency, the reciprocal of time, so
+// "1 / 20 ms" is 50 Hz. Rotational speeds count one revolution as one cycle:
+// 60 rpm is 1 Hz, or 2π rad/s.
+func NewFrequencySystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Frequency",
+		BaseUnit:  "Hertz",
+		Dimension: DimFrequency,
+		Units: map[string]Unit{
+			"Hertz": {
+				Name:       "Hertz",
+				Symbol:     "Hz",
+				Aliases:    []string{"Hz", "hertz", "cps", "cycles per second"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+				Prefixes:   MultiplePrefixes,
+			},
+			"Revolutions per minute": {
+				Name:    "Revolutions per minute",
+				Symbol:  "rpm",
+				Aliases: []string{"rpm", "r/min", "rev/min", "revolutions per minute"},
+				Factor:  ratio("1/60"),
+			},
+			"Revolutions per second": {
+				Name:    "Revolutions per second",
+				Symbol:  "rps",
+				Aliases: []string{"rps", "r/s", "rev/s", "revolutions per second"},
+				Factor:  ratio("1"),
+			},
+			"Radians per second": {
+				Name:    "Radians per second",
+				Symbol:  "rad/s",
+				Aliases: []string{"rad/s", "rad/sec", "radians per second"},
+				Factor:  new(big.Rat).Inv(piTimes("2")),
+				Inexact: true,
+			},
+			"Degrees per second": {
+				Name:    "Degrees per second",
+				Symbol:  "°/s",
+				Aliases: []string{"deg/s", "deg/sec", "degrees per second"},
+				Factor:  ratio("1/360"),
+			},
+		},
+	}
+}
+
+// NewFuelEconomySystem returns units of fuel economy, distance per volume of
+// fuel. Liters per 100 km is fuel per distance instead, the reciprocal, so it
+// has no Factor and converts through its own functions.
+func NewFuelEconomySystem() UnitSystem {
+	// Liters per 100 km to meters per cubic meter, and back: 100 km is 1e5 m
+	// and a liter 1e-3 m³
+	const per100km = 1e8
+	return UnitSystem{
+		Name:      "Fuel economy",
+		BaseUnit:  "Meters per cubic meter",
+		Dimension: DimFuelEconomy,
+		Units: map[string]Unit{
+			"Meters per cubic meter": {
+				Name:    "Meters per cubic meter",
+				Symbol:  "m/m³",
+				Aliases: []string{"m/m3"},
+				Factor:  ratio("1"),
+			},
+			"Kilometers per liter": {
+				Name:    "Kilometers per liter",
+				Symbol:  "km/L",
+				Aliases: []string{"kmpl", "km/l", "kilometers per liter", "kilometres per litre"},
+				Factor:  ratio("1e6"),
+			},
+			"Miles per US gallon": {
+				Name:    "Miles per US gallon",
+				Symbol:  "mpg",
+				Aliases: []string{"mpg", "mpg us", "mpgus", "us mpg", "miles per gallon", "miles per us gallon"},
+				Factor:  new(big.Rat).Quo(ratio("1609.344"), ratio("0.003785411784")),
+				Locale:  LocaleUS,
+			},
+			"Miles per imperial gallon": {
+				Name:    "Miles per imperial gallon",
+				Symbol:  "mpg imp",
+				Aliases: []string{"mpg", "miles per gallon", "mpgimp", "mpg uk", "mpguk", "uk mpg", "imperial mpg", "miles per imperial gallon", "miles per uk gallon"},
+				Factor:  new(big.Rat).Quo(ratio("1609.344"), ratio("0.00454609")),
+				Locale:  LocaleUK,
+			},
+			"Liters per 100 kilometers": {
+				Name:         "Liters per 100 kilometers",
+				Symbol:       "L/100km",
+				Aliases:      []string{"L/100 km", "liters per 100 km", "litres per 100 km", "liters per 100 kilometers", "litres per 100 kilometres"},
+				ToBaseFunc:   func(val float64) float64 { return per100km / val },
+				FromBaseFunc: func(base float64) float64 { return per100km / base },
+			},
+		},
+	}
+}
+
+// NewDensitySystem returns units of density, mass per volume. They have no
+// kind, so they convert to and from concentrations as well.
+func NewDensitySystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Density",
+		BaseUnit:  "Kilograms per cubic meter",
+		Dimension: DimDensity,
+		Units: map[string]Unit{
+			"Kilograms per cubic meter": {
+				Name:    "Kilograms per cubic meter",
+				Symbol:  "kg/m³",
+				Aliases: []string{"kg/m3", "kg/m^3", "kilograms per cubic meter", "kilograms per cubic metre"},
+				Factor:  ratio("1"),
+			},
+			"Grams per cubic centimeter": {
+				Name:    "Grams per cubic centimeter",
+				Symbol:  "g/cm³",
+				Aliases: []string{"g/cm3", "g/cm^3", "g/cc", "grams per cubic centimeter", "grams per cubic centimetre"},
+				Factor:  ratio("1000"),
+			},
+			"Grams per milliliter": {
+				Name:    "Grams per milliliter",
+				Symbol:  "g/mL",
+				Aliases: []string{"grams per milliliter", "grams per millilitre"},
+				Factor:  ratio("1000"),
+			},
+			"Pounds per cubic foot": {
+				Name:    "Pounds per cubic foot",
+				Symbol:  "lb/ft³",
+				Aliases: []string{"lb/ft3", "lb/ft^3", "lb/cu ft", "pcf", "pounds per cubic foot"},
+				Factor:  new(big.Rat).Quo(ratio("0.45359237"), ratio("0.028316846592")),
+			},
+			"Pounds per gallon": {
+				Name:    "Pounds per gallon",
+				Symbol:  "lb/gal",
+				Aliases: []string{"ppg", "pounds per gallon"},
+				Factor:  new(big.Rat).Quo(ratio("0.45359237"), ratio("0.003785411784")),
+			},
+		},
+	}
+}
+
+// NewConcentrationSystem returns units of mass concentration, the mass of a
+// substance per volume of solution. Parts per million and billion are taken
+// by mass in water, where 1 ppm is 1 mg/L.
+func NewConcentrationSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Concentration",
+		BaseUnit:  "Grams per liter",
+		Dimension: DimDensity,
+		Kind:      "concentration",
+		Units: map[string]Unit{
+			"Grams per liter": {
+				Name:    "Grams per liter",
+				Symbol:  "g/L",
+				Aliases: []string{"grams per liter", "grams per litre"},
+				Factor:  ratio("1"),
+			},
+			"Milligrams per liter": {
+				Name:    "Milligrams per liter",
+				Symbol:  "mg/L",
+				Aliases: []string{"milligrams per liter", "milligrams per litre"},
+				Factor:  ratio("1/1000"),
+			},
+			"Grams per deciliter": {
+				Name:    "Grams per deciliter",
+				Symbol:  "g/dL",
+				Aliases: []string{"grams per deciliter", "grams per decilitre"},
+				Factor:  ratio("10"),
+			},
+			"Milligrams per deciliter": {
+				Name:    "Milligrams per deciliter",
+				Symbol:  "mg/dL",
+				Aliases: []string{"milligrams per deciliter", "milligrams per decilitre"},
+				Factor:  ratio("1/100"),
+			},
+			"Parts per million": {
+				Name:    "Parts per million",
+				Symbol:  "ppm",
+				Aliases: []string{"ppm", "parts per million"},
+				Factor:  ratio("1/1000"),
+			},
+			"Parts per billion": {
+				Name:    "Parts per billion",
+				Symbol:  "ppb",
+				Aliases: []string{"ppb", "parts per billion"},
+				Factor:  ratio("1/1000000"),
+			},
+			"Percent weight per volume": {
+				Name:    "Percent weight per volume",
+				Symbol:  "% w/v",
+				Aliases: []string{"%w/v", "w/v%", "w/v", "percent w/v"},
+				Factor:  ratio("10"),
+			},
+		},
+	}
+}
+
+// NewCurrentSystem returns units of electric current. The ampere takes only
+// the common prefixes, since "pA" would read as the pascal in "5 pa".
+func NewCurrentSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Current",
+		BaseUnit:  "Amperes",
+		Dimension: DimCurrent,
+		Units: map[string]Unit{
+			"Amperes": {
+				Name:    "Amperes",
+				Symbol:  "A",
+				Aliases: []string{"amp", "amps", "ampere", "amperes"},
+				Factor:  ratio("1"),
+			},
+			"Kiloamperes": {
+				Name:    "Kiloamperes",
+				Symbol:  "kA",
+				Aliases: []string{"kiloamp", "kiloamps", "kiloampere", "kiloamperes"},
+				Factor:  ratio("1000"),
+			},
+			"Milliamperes": {
+				Name:    "Milliamperes",
+				Symbol:  "mA",
+				Aliases: []string{"milliamp", "milliamps", "milliampere", "milliamperes"},
+				Factor:  ratio("1/1000"),
+			},
+			"Microamperes": {
+				Name:    "Microamperes",
+				Symbol:  "µA",
+				Aliases: []string{"uA", "μA", "microamp", "microamps", "microampere", "microamperes"},
+				Factor:  ratio("1/1000000"),
+			},
+		},
+	}
+}
+
+// NewChargeSystem returns units of electric charge, including the
+// ampere-hours of battery capacities.
+func NewChargeSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Charge",
+		BaseUnit:  "Coulombs",
+		Dimension: DimCharge,
+		Units: map[string]Unit{
+			"Coulombs": {
+				Name:       "Coulombs",
+				Symbol:     "C",
+				Aliases:    []string{"coulomb", "coulombs"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+				secondary:  true,
+			},
+			"Ampere hours": {
+				Name:    "Ampere hours",
+				Symbol:  "Ah",
+				Aliases: []string{"amp hour", "amp hours", "ampere hour", "ampere hours"},
+				Factor:  ratio("3600"),
+			},
+			"Milliampere hours": {
+				Name:    "Milliampere hours",
+				Symbol:  "mAh",
+				Aliases: []string{"milliamp hour", "milliamp hours", "milliampere hour", "milliampere hours"},
+				Factor:  ratio("3.6"),
+			},
+		},
+	}
+}
+
+func NewVoltageSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Voltage",
+		BaseUnit:  "Volts",
+		Dimension: DimVoltage,
+		Units: map[string]Unit{
+			"Volts": {
+				Name:       "Volts",
+				Symbol:     "V",
+				Aliases:    []string{"volt", "volts"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+			},
+		},
+	}
+}
+
+func NewResistanceSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Resistance",
+		BaseUnit:  "Ohms",
+		Dimension: DimResistance,
+		Units: map[string]Unit{
+			"Ohms": {
+				Name:   "Ohms",
+				Symbol: "Ω",
+				// The first alias is the ohm sign, U+2126, which looks the same
+				Aliases:    []string{"Ω", "ohm", "ohms"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+			},
+		},
+	}
+}
+
+func NewCapacitanceSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Capacitance",
+		BaseUnit:  "Farads",
+		Dimension: DimCapacitance,
+		Units: map[string]Unit{
+			"Farads": {
+				Name:       "Farads",
+				Symbol:     "F",
+				Aliases:    []string{"farad", "farads"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+				secondary:  true,
+			},
+		},
+	}
+}
+
+func NewConductanceSystem() UnitSystem {
+	return UnitSystem{
+		Name:      "Conductance",
+		BaseUnit:  "Siemens",
+		Dimension: DimConductance,
+		Units: map[string]Unit{
+			"Siemens": {
+				Name:       "Siemens",
+				Symbol:     "S",
+				Aliases:    []string{"siemens"},
+				Factor:     ratio("1"),
+				Prefixable: true,
+			},
+		},
+	}
 }
@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTorqueAndEnergyKinds(t *testing.T) {
+	c := NewConverter(MustRegisterSystems())
+	tests := []struct {
+		input string
+		want  float64
+		// incompatible is set when the kinds must not convert.
+		incompatible bool
+	}{
+		// The newton meter's own names are torque
+		{input: "1 N·m in J", incompatible: true},
+		{input: "1 Nm in J", incompatible: true},
+		{input: "1 N*m in J", incompatible: true},
+		{input: "1 N*m in lbf*ft", want: 0.7375621492772654},
+		// Products of other units have no kind
+		{input: "1 N m in J", want: 1},
+		{input: "10 N * 5 m in J", want: 50},
+		{input: "1 W·s in J", want: 1},
+		{input: "10 N * 5 m in N·m", want: 50},
+	}
+	for _, tt := range tests {
+		result, err := c.Process(tt.input)
+		var incompatible *IncompatibleUnitsError
+		switch {
+		case tt.incompatible:
+			if !errors.As(err, &incompatible) {
+				t.Errorf("Process(%q) error = %v, want *IncompatibleUnitsError", tt.input, err)
+			}
+		case err != nil:
+			t.Errorf("Process(%q) error = %v", tt.input, err)
+		case result.Value != tt.want:
+			t.Errorf("Process(%q) = %v, want %v", tt.input, result.Value, tt.want)
+		}
+	}
+}
+
+func TestGaugePressures(t *testing.T) {
+	c := NewConverter(MustRegisterSystems())
+	tests := []struct {
+		input  string
+		want   float64
+		symbol string
+	}{
+		{"30 psig - 10 psig", 20, "psi"},
+		{"3 barg - 1 barg", 2, "bar"},
+		{"30 psig - 10 psig in psi", 20, "psi"},
+		{"30 psig + 2 psi", 32, "psig"},
+		{"1 atm in psig", 0, "psig"},
+		{"0 barg in bar", 1.01325, "bar"},
+	}
+	for _, tt := range tests {
+		result, err := c.Process(tt.input)
+		if err != nil {
+			t.Errorf("Process(%q) error = %v", tt.input, err)
+			continue
+		}
+		if result.Value != tt.want || result.UnitSymbol != tt.symbol {
+			t.Errorf("Process(%q) = %v %s, want %v %s", tt.input, result.Value, result.UnitSymbol, tt.want, tt.symbol)
+		}
+	}
+
+	for _, input := range []string{"30 psig + 2 psig", "2 * 30 psig", "30 psig / 2"} {
+		_, err := c.Process(input)
+		var absolute *AbsoluteValueError
+		if !errors.As(err, &absolute) {
+			t.Errorf("Process(%q) error = %v, want *AbsoluteValueError", input, err)
+		}
+	}
+}
@@ -0,0 +1,477 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Unit expressions name derived units such as "kg*m/s^2", "N·m", "W/(m²·K)"
+// or "kWh/100km". The grammar is
+//
+//	expr     = term { ("*" | "·" | "×" | "/" | "per") term | term }
+//	term     = number [factor] | factor
+//	factor   = (name | "(" expr ")") [exponent]
+//	exponent = "^" ["-"] digits | "²" | "³"
+//
+// Adjacent terms multiply, and a number directly before a unit binds to it,
+// so "kWh/100km" divides by a hundred kilometers.
+
+type unitExprToken struct {
+	kind rune // 'n' number, 'a' name, or the operator itself
+	text string
+}
+
+// unitPart is one factor of a derived unit's symbol and name.
+type unitPart struct {
+	symbol string
+	name   string
+	exp    int
+}
+
+// derivedUnit is a product of unit powers and a numeric factor, all expressed
+// in coherent base units.
+type derivedUnit struct {
+	factor *big.Rat
+	dim    Dimension
+	parts  []unitPart
+	// inexact is set when any of the units has an Inexact factor.
+	inexact bool
+}
+
+type unitExprParser struct {
+	tokens []unitExprToken
+	pos    int
+	lookup func(string) ([]Unit, bool)
+	// outOfRange is set when a power leaves the range of a Dimension.
+	outOfRange bool
+}
+
+// errNoUnitExpr is returned for text that is not a unit expression.
+var errNoUnitExpr = errors.New("not a unit expression")
+
+// parseUnitExpr returns every derived unit the expression could denote, one
+// per combination of ambiguous names. It returns an ExponentRangeError for
+// powers no dimension holds, and errNoUnitExpr for anything else that is not
+// a unit.
+func parseUnitExpr(s string, lookup func(string) ([]Unit, bool)) ([]Unit, error) {
+	tokens, ok := lexUnitExpr(s)
+	if !ok || len(tokens) == 0 {
+		return nil, errNoUnitExpr
+	}
+
+	p := &unitExprParser{tokens: tokens, lookup: lookup}
+	derived, ok := p.expr()
+	if p.outOfRange {
+		return nil, &ExponentRangeError{Unit: s}
+	}
+	if !ok || p.pos != len(p.tokens) {
+		return nil, errNoUnitExpr
+	}
+
+	units := make([]Unit, len(derived))
+	for i, d := range derived {
+		units[i] = d.unit()
+	}
+	return units, nil
+}
+
+func lexUnitExpr(s string) ([]unitExprToken, bool) {
+	var tokens []unitExprToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, unitExprToken{kind: 'n', text: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '°' || unicode.Is(unicode.Sc, r):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '°' || unicode.Is(unicode.Sc, runes[i])) {
+				i++
+			}
+			name := string(runes[start:i])
+			if strings.EqualFold(name, "per") {
+				tokens = append(tokens, unitExprToken{kind: '/', text: name})
+			} else {
+				tokens = append(tokens, unitExprToken{kind: 'a', text: name})
+			}
+		case strings.ContainsRune("*·×/^()²³-", r):
+			kind := r
+			if r == '·' || r == '×' {
+				kind = '*'
+			}
+			tokens = append(tokens, unitExprToken{kind: kind, text: string(r)})
+			i++
+		default:
+			return nil, false
+		}
+	}
+	return tokens, true
+}
+
+func (p *unitExprParser) peek() rune {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].kind
+	}
+	return 0
+}
+
+func (p *unitExprParser) expr() ([]derivedUnit, bool) {
+	left, ok := p.term()
+	if !ok {
+		return nil, false
+	}
+	for {
+		inverse := false
+		switch p.peek() {
+		case '*':
+			p.pos++
+		case '/':
+			p.pos++
+			inverse = true
+		case 'n', 'a', '(':
+		default:
+			return left, true
+		}
+
+		right, ok := p.term()
+		if !ok {
+			return nil, false
+		}
+		if left, ok = p.combine(left, right, inverse); !ok {
+			return nil, false
+		}
+	}
+}
+
+func (p *unitExprParser) term() ([]derivedUnit, bool) {
+	if p.peek() != 'n' {
+		return p.factor()
+	}
+
+	text := p.tokens[p.pos].text
+	p.pos++
+	value, ok := new(big.Rat).SetString(text)
+	if !ok || value.Sign() == 0 {
+		return nil, false
+	}
+	number := []derivedUnit{{factor: value, parts: []unitPart{{symbol: text, name: text, exp: 1}}}}
+
+	if kind := p.peek(); kind != 'a' && kind != '(' {
+		return number, true
+	}
+	right, ok := p.factor()
+	if !ok {
+		return nil, false
+	}
+	return p.combine(number, right, false)
+}
+
+func (p *unitExprParser) factor() ([]derivedUnit, bool) {
+	var base []derivedUnit
+	switch p.peek() {
+	case 'a':
+		name := p.tokens[p.pos].text
+		p.pos++
+		var ok bool
+		if base, ok = p.name(name); !ok {
+			return nil, false
+		}
+	case '(':
+		p.pos++
+		var ok bool
+		if base, ok = p.expr(); !ok || p.peek() != ')' {
+			return nil, false
+		}
+		p.pos++
+	default:
+		return nil, false
+	}
+
+	exp, ok := p.exponent()
+	if !ok {
+		return nil, false
+	}
+	return p.pow(base, exp)
+}
+
+func (p *unitExprParser) exponent() (int, bool) {
+	switch p.peek() {
+	case '²':
+		p.pos++
+		return 2, true
+	case '³':
+		p.pos++
+		return 3, true
+	case '^':
+		p.pos++
+		sign := 1
+		if p.peek() == '-' {
+			p.pos++
+			sign = -1
+		}
+		if p.peek() != 'n' {
+			return 0, false
+		}
+		exp, err := strconv.Atoi(p.tokens[p.pos].text)
+		if err != nil {
+			return 0, false
+		}
+		// Checked before powDerived multiplies the factor out that often
+		if exp > maxExponent {
+			p.outOfRange = true
+			return 0, false
+		}
+		p.pos++
+		return sign * exp, true
+	}
+	return 1, true
+}
+
+// name looks up a single unit. Names such as "s2" that are not registered
+// themselves are read as a power of the unit before the digits.
+func (p *unitExprParser) name(name string) ([]derivedUnit, bool) {
+	exp := 1
+	key := name
+	units, ok := p.lookup(name)
+	if !ok {
+		key = strings.TrimRightFunc(name, unicode.IsDigit)
+		if key == name || key == "" {
+			return nil, false
+		}
+		if exp, _ = strconv.Atoi(name[len(key):]); exp == 0 {
+			return nil, false
+		}
+		if exp > maxExponent {
+			p.outOfRange = true
+			return nil, false
+		}
+		if units, ok = p.lookup(key); !ok {
+			return nil, false
+		}
+	}
+	units = exactCase(key, units)
+
+	var derived []derivedUnit
+	for _, unit := range units {
+		// Only the scale of a unit carries into a compound: "W/(m·K)" is
+		// per kelvin of difference, whatever the offset of the scale.
+		if unit.Factor == nil {
+			continue
+		}
+		powered, ok := p.pow([]derivedUnit{{
+			factor:  unit.Factor,
+			dim:     unit.Dimension,
+			parts:   []unitPart{{symbol: unit.Symbol, name: unit.Name, exp: 1}},
+			inexact: unit.Inexact,
+		}}, exp)
+		if !ok {
+			return nil, false
+		}
+		derived = append(derived, powered...)
+	}
+	return derived, len(derived) > 0
+}
+
+// exactCase keeps the units spelled exactly as s, if any are. Within a
+// compound, case alone tells symbols apart: the "s" of "m/s^2" is the
+// second, not the siemens.
+func exactCase(s string, units []Unit) []Unit {
+	var exact []Unit
+	for _, unit := range units {
+		if unit.hasKey(s) {
+			exact = append(exact, unit)
+		}
+	}
+	if len(exact) == 0 {
+		return units
+	}
+	return exact
+}
+
+// combine and pow are combineDerived and powDerived, noting a power out of
+// range.
+func (p *unitExprParser) combine(left, right []derivedUnit, inverse bool) ([]derivedUnit, bool) {
+	combined, err := combineDerived(left, right, inverse)
+	p.outOfRange = p.outOfRange || err != nil
+	return combined, err == nil
+}
+
+func (p *unitExprParser) pow(units []derivedUnit, exp int) ([]derivedUnit, bool) {
+	powered, err := powDerived(units, exp)
+	p.outOfRange = p.outOfRange || err != nil
+	return powered, err == nil
+}
+
+func combineDerived(left, right []derivedUnit, inverse bool) ([]derivedUnit, error) {
+	if inverse {
+		var err error
+		if right, err = powDerived(right, -1); err != nil {
+			return nil, err
+		}
+	}
+
+	var combined []derivedUnit
+	for _, l := range left {
+		for _, r := range right {
+			dim, err := l.dim.product(r.dim, false)
+			if err != nil {
+				return nil, err
+			}
+			parts := append(append([]unitPart{}, l.parts...), r.parts...)
+			combined = append(combined, derivedUnit{
+				factor:  new(big.Rat).Mul(l.factor, r.factor),
+				dim:     dim,
+				parts:   parts,
+				inexact: l.inexact || r.inexact,
+			})
+		}
+	}
+	return combined, nil
+}
+
+func powDerived(units []derivedUnit, exp int) ([]derivedUnit, error) {
+	if exp == 1 {
+		return units, nil
+	}
+
+	powered := make([]derivedUnit, len(units))
+	for i, u := range units {
+		dim, err := u.dim.Pow(exp)
+		if err != nil {
+			return nil, err
+		}
+		// The dimension does not bound dimensionless units such as the
+		// radian, so every unit's own power is checked as well: nested
+		// powers multiply, and "((mrad^127)^127)^127" is out of range
+		parts := make([]unitPart, len(u.parts))
+		for j, part := range u.parts {
+			part.exp *= exp
+			if abs(part.exp) > maxExponent {
+				return nil, fmt.Errorf("exponent out of range: %s^%d", part.symbol, part.exp)
+			}
+			parts[j] = part
+		}
+		powered[i] = derivedUnit{factor: ratPow(u.factor, exp), dim: dim, parts: parts, inexact: u.inexact}
+	}
+	return powered, nil
+}
+
+// ratPow raises r to the power exp, squaring rather than multiplying exp
+// times.
+func ratPow(r *big.Rat, exp int) *big.Rat {
+	n := big.NewInt(int64(abs(exp)))
+	num := new(big.Int).Exp(r.Num(), n, nil)
+	den := new(big.Int).Exp(r.Denom(), n, nil)
+	if exp < 0 {
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den)
+}
+
+func (d derivedUnit) unit() Unit {
+	var numSymbols, denSymbols, numNames, denNames []string
+	for _, part := range mergeParts(d.parts) {
+		switch {
+		case part.exp > 0:
+			numSymbols = append(numSymbols, part.symbol+superscript(part.exp))
+			numNames = append(numNames, part.name+powerName(part.exp))
+		case part.exp < 0:
+			denSymbols = append(denSymbols, part.symbol+superscript(-part.exp))
+			denNames = append(denNames, part.name+powerName(-part.exp))
+		}
+	}
+
+	symbol := joinSymbols(numSymbols)
+	name := strings.Join(numNames, " ")
+	if symbol == "" {
+		symbol, name = "1", "1"
+	}
+	if len(denSymbols) > 0 {
+		den := joinSymbols(denSymbols)
+		if strings.Contains(den, "·") {
+			den = "(" + den + ")"
+		}
+		symbol += "/" + den
+		name += " per " + strings.Join(denNames, " ")
+	}
+
+	return Unit{
+		Name:      name,
+		Symbol:    symbol,
+		Dimension: d.dim,
+		Factor:    d.factor,
+		Inexact:   d.inexact,
+	}.withFactorFuncs()
+}
+
+// mergeParts adds up the exponents of repeated units, so "ft*ft" is ft² and
+// "m*s/s" is m.
+func mergeParts(parts []unitPart) []unitPart {
+	var merged []unitPart
+	index := make(map[string]int)
+	for _, part := range parts {
+		if i, ok := index[part.symbol]; ok {
+			merged[i].exp += part.exp
+			continue
+		}
+		index[part.symbol] = len(merged)
+		merged = append(merged, part)
+	}
+	return merged
+}
+
+// joinSymbols separates unit symbols with "·" but writes a number straight
+// before the unit it scales ("100km").
+func joinSymbols(symbols []string) string {
+	var b strings.Builder
+	for i, symbol := range symbols {
+		if i > 0 {
+			if _, err := strconv.ParseFloat(symbols[i-1], 64); err != nil {
+				b.WriteString("·")
+			}
+		}
+		b.WriteString(symbol)
+	}
+	return b.String()
+}
+
+func superscript(exp int) string {
+	switch exp {
+	case 1:
+		return ""
+	case 2:
+		return "²"
+	case 3:
+		return "³"
+	}
+	return fmt.Sprintf("^%d", exp)
+}
+
+func powerName(exp int) string {
+	switch exp {
+	case 1:
+		return ""
+	case 2:
+		return " squared"
+	case 3:
+		return " cubed"
+	}
+	return fmt.Sprintf(" to the %d", exp)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
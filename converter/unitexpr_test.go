@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUnitExprExponentRange(t *testing.T) {
+	c := NewConverter(MustRegisterSystems())
+	tests := []struct {
+		input      string
+		outOfRange bool
+	}{
+		{"1 m^200 in m", true},
+		{"1 m^127 in m^127", false},
+		{"1 mrad*(mrad^2)^63 in rad", false},
+		{"1 mrad*(mrad^2)^64 in rad", true},
+		// Nested powers multiply, and a dimensionless unit has no
+		// dimension to bound them
+		{"1 mrad*((mrad^127)^127)^127 in rad", true},
+		{"1 mrad*(((mrad^127)^127)^127)^127 in rad", true},
+	}
+	for _, tt := range tests {
+		start := time.Now()
+		_, err := c.Process(tt.input)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Process(%q) took %v", tt.input, elapsed)
+		}
+		var rangeErr *ExponentRangeError
+		if got := errors.As(err, &rangeErr); got != tt.outOfRange {
+			t.Errorf("Process(%q) error = %v, want out of range: %v", tt.input, err, tt.outOfRange)
+		}
+	}
+}
+
+func TestRatPow(t *testing.T) {
+	tests := []struct {
+		r    string
+		exp  int
+		want string
+	}{
+		{"2/3", 3, "8/27"},
+		{"2/3", -2, "9/4"},
+		{"1000", 0, "1"},
+		{"-1/2", 3, "-1/8"},
+	}
+	for _, tt := range tests {
+		if got := ratPow(ratio(tt.r), tt.exp); got.Cmp(ratio(tt.want)) != 0 {
+			t.Errorf("ratPow(%s, %d) = %s, want %s", tt.r, tt.exp, got.RatString(), tt.want)
+		}
+	}
+}
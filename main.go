@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -10,6 +11,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 var testCases = []string{
@@ -24,21 +27,41 @@ var testCases = []string{
 	"1 leter in ml",
 	"2 gallens in L",
 	"one gallon and 2.5 litres in ml",
+	"a cup and a half in ml",
+	"two and a half cups in ml",
 
 	// Length
 	"1 km in miles",
 	"a foot and 5 inches in cm",
 	"100 meters + 0.1km in ft",
 
+	// Astronomical and nautical
+	"4.2 light years in km",
+	"1 au in km",
+	"1 ly in au",
+	"1 parsec in ly",
+	"10 nmi in km",
+	"1000 survey feet in m",
+	"1000 ftUS in ft",
+	"1 mile in furlongs",
+
 	// Weight
 	"1kg in lbs",
 	"two pounds and 8 ounces in grams",
 	"100g + .5kg",
 
+	// US and imperial
+	"1 imp gal in us gal",
+	"2 uk pints in ml",
+	"1 long ton in tonnes",
+
 	// Temperature
 	"100 C in F",
 	"212 f in C",
 	"0c in k",
+	"20 C + 10 delta F in F",
+	"30 c - 10 c in f",
+	"20 C + 10 C in F",
 
 	// Area
 	"100 sqft in m2",
@@ -50,10 +73,125 @@ var testCases = []string{
 
 	// Compound
 	"10 km / 2 hr in m/s",
+	"9.81 m/s² in ft/s^2",
+	"30 lb/in2 in kg/cm²",
+	"1 kg*m/s^2 in g·cm/s²",
+	"60 miles per hour in km/h",
 
 	// Time
 	"1 day in hours",
 
+	// Energy
+	"1 kWh in MJ",
+	"2000 Cal in kWh",
+	"1 therm in BTU",
+	"15 kWh/100km in Wh/km",
+
+	// Power
+	"3 kW * 2 h in kWh",
+	"100 PS in hp",
+	"12000 BTU/h in TR",
+
+	// Pressure
+	"1 atm in psi",
+	"32 psig in kPa",
+	"1 atm in psig",
+	"30 psig - 10 psig in psi",
+	"30 psig + 2 psig in psig",
+	"1013.25 hPa in inHg",
+
+	// Data
+	"4.7 GB in GiB",
+	"100 Mbps in MB/s",
+	"1 KB in B",
+	"1 GiB / 100 Mbps in s",
+
+	// Force and torque
+	"1 kN in lbf",
+	"10 N * 5 m in J",
+	"50 N·m in lbf·ft",
+	"50 N·m in J",
+
+	// Angles
+	"90° in rad",
+	"40°26'46\" in deg",
+	"12d 30m in deg",
+	"1 turn in gon",
+
+	// Frequency
+	"1 / 20 ms in Hz",
+	"2.4 GHz in MHz",
+	"3000 rpm in rad/s",
+	"60 rpm in Hz",
+	"50 Hz in rpm",
+	"1 Hz in rad/s",
+	"360 deg/s in rpm",
+
+	// Fuel economy
+	"30 mpg in L/100km",
+	"8 L/100km in mpg imp",
+	"400 miles / 12 gallons in km/L",
+
+	// Density and concentration
+	"1 g/cm³ in lb/ft³",
+	"8.34 lb/gal in g/mL",
+	"250 mg/L in ppm",
+	"0.9 %w/v in mg/dL",
+
+	// Ingredients
+	"2 cups of sugar in g",
+	"200 g butter in tbsp",
+	"1 cup of flour in ounces",
+
+	// Electrical
+	"3000 mAh * 3.7 V in Wh",
+	"12 V / 2 A",
+	"4.7 kΩ in ohm",
+	"10 µF * 5 V in µC",
+	"100 F in C",
+	"100 F",
+	"5 F in µF",
+
+	// Typography
+	"12pt in px at 144 dpi",
+	"1.5 rem in px",
+	"48 dp in px at 480 dpi",
+	"1 pt",
+	"1 pt in mm",
+	"2 em in px at 144 dpi and 20 px",
+	"2 em in px at 20 px and",
+
+	// Fractions
+	"1/3 cup in tbsp",
+	"1 1/4 cups in ml",
+
+	// Expressions
+	"1 m + 2 m * 3",
+	"(1 m + 2 m) * 3 in ft",
+	"3 * 2 ft",
+	"5 ft 3 in in cm",
+	"-40 f in c",
+	"5 km in",
+	"1 m + 1",
+
+	// Dimension checks
+	"1 kg + 3 m in L",
+	"5 km in hours",
+
+	// Errors
+	"1 m^200 in m",
+	"1 m / 0",
+	"2 * 8 L/100km",
+	"0 L/100km in mpg",
+	"2 m of sugar in g",
+	"1 kilometrs in m",
+	"1 dala in L",
+
+	// Ambiguous aliases
+	"8 oz in g",
+	"8 oz in ml",
+	"8 oz",
+
 	// Previously failing
 	"two pints + a half cup in floz",
 	"one gallon + 2.5 litres in ml",
@@ -61,31 +199,54 @@ var testCases = []string{
 	"two pounds + 8 ounces in grams",
 }
 
-func printHelp() {
+func printHelp(registry *converter.Registry) {
 	fmt.Println("Usage: nlp-unit-converter [expression]")
 	fmt.Println("       nlp-unit-converter [flags]")
 	fmt.Println("\nEvaluates a natural language expression of units and converts them.")
 	fmt.Println("\nFlags:")
 	fmt.Println("  -h, --help\t\t\tPrints this help message.")
 	fmt.Println("  -ss, --start-server [port]\tStarts a web API server (default port: 8080).")
+	fmt.Println("  -x, --exact\t\t\tAlso prints the exact fractional result.")
+	fmt.Println("  -u, --units-file [path]\tLoads custom units from a JSON definitions file.")
+	fmt.Println("  -r, --rates-file [path]\tLoads exchange rates from a JSON or CSV snapshot.")
+	fmt.Println("  -l, --locale [us|uk]\t\tReads gallons, pints, fluid ounces and tons as US or UK units.")
+	fmt.Println("  --dpi [n]\t\t\tSets the pixels per inch of px, em and rem (default: 96).")
+	fmt.Println("  --font-size [px]\t\tSets the size of em and rem in pixels (default: 16).")
 	fmt.Println("\nServer Examples:")
 	fmt.Println("  nlp-unit-converter -ss\t\tStart server on default port 8080")
 	fmt.Println("  nlp-unit-converter --start-server 7000\tStart server on port 7000")
 	fmt.Println("\nConversion Examples:")
 	fmt.Println("| Expression                           | Result                                  |")
 	fmt.Println("|------------------------------------|-----------------------------------------|")
-	unitMap := converter.MustRegisterSystems()
-	conv := converter.NewConverter(unitMap)
+	conv := converter.NewConverter(registry)
 	for _, tc := range testCases {
 		result, err := conv.Process(tc)
 		if err != nil {
 			fmt.Printf("| %-34s | Error: %-29s |\n", tc, err.Error())
 		} else {
-			resultStr := fmt.Sprintf("%g %s (%s)", result.Value, result.UnitSymbol, result.UnitName)
-			fmt.Printf("| %-34s | %-39s |\n", tc, resultStr)
+			fmt.Printf("| %-34s | %-39s |\n", tc, formatResult(result))
 		}
 	}
 	fmt.Println("|------------------------------------|-----------------------------------------|")
+
+	var ingredients []string
+	for _, ingredient := range registry.Ingredients() {
+		ingredients = append(ingredients, ingredient.Name)
+	}
+	fmt.Printf("\nIngredients (\"2 cups of sugar in g\"): %s\n", strings.Join(ingredients, ", "))
+}
+
+// formatResult writes a result as "value symbol (name)", or just the value
+// for a plain number.
+func formatResult(result *converter.Result) string {
+	if result.UnitSymbol == "" {
+		return fmt.Sprintf("%g", result.Value)
+	}
+	text := fmt.Sprintf("%g %s (%s)", result.Value, result.UnitSymbol, result.UnitName)
+	if !result.RatesAsOf.IsZero() {
+		text += fmt.Sprintf(", rates as of %s", result.RatesAsOf.Format(time.DateOnly))
+	}
+	return text
 }
 
 const htmlPage = `<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><meta name="viewport" content="width=device-width,initial-scale=1"><title>NLP Unit Converter</title><style>*{box-sizing:border-box;margin:0;padding:0}body{font-family:system-ui,-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,Helvetica,Arial,sans-serif;background-color:#f3f4f6;display:flex;align-items:center;justify-content:center;min-height:100vh}.container{width:100%;max-width:448px;margin:1rem;background-color:#fff;border-radius:12px;border:1px solid #e5e7eb;padding:32px}.container>div:not(:first-child){margin-top:24px}h1{font-size:1.5rem;font-weight:700;text-align:center}p{color:#6b7280;text-align:center;margin-top:4px}#expression-input{width:100%;padding:12px 16px;background-color:#f9fafb;border:1px solid #d1d5db;border-radius:8px;font-size:1rem}#expression-input:focus{outline:2px solid #3b82f6}#convert-btn{width:100%;margin-top:16px;background-color:#2563eb;color:#fff;font-weight:600;padding:12px 16px;border:none;border-radius:8px;cursor:pointer}#convert-btn:disabled{background-color:#9ca3af;cursor:not-allowed}#result-display{padding:16px;border-radius:8px;text-align:center;font-weight:500;margin-top:16px}.hidden{display:none}.success{background-color:#d1fae5;color:#065f46}.error{background-color:#fee2e2;color:#991b1b}.examples-section{padding-top:16px;border-top:1px solid #e5e7eb}.examples-section h3{font-size:.875rem;font-weight:600;color:#4b5563;margin-bottom:12px;text-align:center}#examples-list{list-style:none;display:flex;flex-wrap:wrap;justify-content:center;gap:8px}.example-btn{padding:4px 12px;background-color:#f3f4f6;color:#374151;font-size:.875rem;border-radius:9999px;border:1px solid #d1d5db;cursor:pointer}</style></head><body><div class="container"><div><h1>Unit Converter</h1><p>Convert units using natural language.</p></div><div><input type="text" id="expression-input" placeholder="e.g., 2 liters to ml"><button id="convert-btn">Convert</button></div><div id="result-display" class="hidden"></div><div class="examples-section"><h3>Try these:</h3><ul id="examples-list"></ul></div></div><script>const expressionInput = document.getElementById('expression-input');
@@ -114,7 +275,7 @@ const htmlPage = `<!DOCTYPE html><html lang="en"><head><meta charset="UTF-8"><me
                 if (data.error) {
                     showResult('Error: ' + data.error, false);
                 } else {
-                    const resultText = data.value + ' ' + data.unit_symbol + ' (' + data.unit_name + ')';
+                    const resultText = data.unit_symbol ? data.value + ' ' + data.unit_symbol + ' (' + data.unit_name + ')' + (data.as_of ? ', rates as of ' + data.as_of : '') : String(data.value);
                     showResult(resultText, true);
                 }
             } catch (error) {
@@ -161,18 +322,269 @@ type APIResponse struct {
 	Value      float64 `json:"value"`
 	UnitSymbol string  `json:"unit_symbol"`
 	UnitName   string  `json:"unit_name"`
-	Error      string  `json:"error,omitempty"`
+	Exact      string  `json:"exact,omitempty"`
+	// AsOf is the date of the exchange rates of a currency conversion.
+	AsOf  string `json:"as_of,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Code classifies Error for clients. errorResponse sets one per error
+	// type of Process, and the units endpoint answers an unknown alias with
+	// unknown_unit:
+	//
+	//	unknown_unit             UnknownUnitError, an unknown currency included
+	//	unknown_ingredient       UnknownIngredientError
+	//	ambiguous_unit           AmbiguousUnitError
+	//	incompatible_units       IncompatibleUnitsError
+	//	invalid_number           InvalidNumberError
+	//	empty_expression         EmptyExpressionError
+	//	syntax_error             SyntaxError, a bad "at" clause included
+	//	exponent_out_of_range    ExponentRangeError
+	//	unmeasurable_ingredient  UnmeasurableIngredientError
+	//	nonlinear_unit           NonLinearUnitError
+	//	absolute_value           AbsoluteValueError: adding absolute
+	//	                         temperatures or scaling gauge pressures
+	//	division_by_zero         DivisionByZeroError
+	//	infinite_result          InfiniteResultError
+	//	conversion_error         any other error
+	//
+	// bad_request is a request the server refuses before converting: not a
+	// GET, a query over 100 characters or a bad locale. Definitions and rates
+	// files are checked when the server starts, and a rates file that fails
+	// to reload keeps the previous rates, so neither has a code.
+	Code        string   `json:"code,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	Position    *int     `json:"position,omitempty"`
+}
+
+// errorResponse describes a conversion error with a machine-readable code.
+func errorResponse(err error) APIResponse {
+	response := APIResponse{Error: err.Error(), Code: "conversion_error"}
+
+	var unknown *converter.UnknownUnitError
+	var unknownIngredient *converter.UnknownIngredientError
+	var ambiguous *converter.AmbiguousUnitError
+	var incompatible *converter.IncompatibleUnitsError
+	var invalidNumber *converter.InvalidNumberError
+	var empty *converter.EmptyExpressionError
+	var syntax *converter.SyntaxError
+	var exponent *converter.ExponentRangeError
+	var unmeasurable *converter.UnmeasurableIngredientError
+	var nonLinear *converter.NonLinearUnitError
+	var absolute *converter.AbsoluteValueError
+	var divisionByZero *converter.DivisionByZeroError
+	var infinite *converter.InfiniteResultError
+	switch {
+	case errors.As(err, &unknown):
+		response.Code = "unknown_unit"
+		response.Suggestions = unknown.Suggestions
+		response.Position = &unknown.Position
+	case errors.As(err, &unknownIngredient):
+		response.Code = "unknown_ingredient"
+		response.Suggestions = unknownIngredient.Suggestions
+		response.Position = &unknownIngredient.Position
+	case errors.As(err, &ambiguous):
+		response.Code = "ambiguous_unit"
+	case errors.As(err, &incompatible):
+		response.Code = "incompatible_units"
+	case errors.As(err, &invalidNumber):
+		response.Code = "invalid_number"
+		response.Position = &invalidNumber.Position
+	case errors.As(err, &empty):
+		response.Code = "empty_expression"
+	case errors.As(err, &syntax):
+		response.Code = "syntax_error"
+		response.Position = &syntax.Position
+	case errors.As(err, &exponent):
+		response.Code = "exponent_out_of_range"
+		response.Position = &exponent.Position
+	case errors.As(err, &unmeasurable):
+		response.Code = "unmeasurable_ingredient"
+		response.Position = &unmeasurable.Position
+	case errors.As(err, &nonLinear):
+		response.Code = "nonlinear_unit"
+		response.Position = &nonLinear.Position
+	case errors.As(err, &absolute):
+		response.Code = "absolute_value"
+		response.Position = &absolute.Position
+	case errors.As(err, &divisionByZero):
+		response.Code = "division_by_zero"
+		response.Position = &divisionByZero.Position
+	case errors.As(err, &infinite):
+		response.Code = "infinite_result"
+		response.Position = &infinite.Position
+	}
+	return response
+}
+
+// SystemInfo and UnitInfo describe the unit catalog served at /units.
+type SystemInfo struct {
+	Name      string     `json:"name"`
+	Dimension string     `json:"dimension"`
+	Kind      string     `json:"kind,omitempty"`
+	BaseUnit  string     `json:"base_unit"`
+	Units     []UnitInfo `json:"units"`
+}
+
+type UnitInfo struct {
+	Name      string   `json:"name"`
+	Symbol    string   `json:"symbol"`
+	Aliases   []string `json:"aliases,omitempty"`
+	System    string   `json:"system"`
+	Dimension string   `json:"dimension"`
+	Kind      string   `json:"kind,omitempty"`
+	Locale    string   `json:"locale,omitempty"`
+}
+
+func newUnitInfo(unit converter.Unit) UnitInfo {
+	return UnitInfo{
+		Name:      unit.Name,
+		Symbol:    unit.Symbol,
+		Aliases:   unit.Aliases,
+		System:    unit.System,
+		Dimension: unit.Dimension.String(),
+		Kind:      unit.Kind,
+		Locale:    string(unit.Locale),
+	}
+}
+
+// loadRegistry registers the built-in units and, when files are given, the
+// exchange rates and custom units they define.
+func loadRegistry(unitsFile, ratesFile string) *converter.Registry {
+	registry, err := newRegistry(unitsFile, ratesFile)
+	if err != nil {
+		log.Fatalf("❌ Failed to load %v\n", err)
+	}
+	return registry
+}
+
+func newRegistry(unitsFile, ratesFile string) (*converter.Registry, error) {
+	registry := converter.MustRegisterSystems()
+	// Rates come first, so custom units of the currency dimension join them
+	if ratesFile != "" {
+		if err := registry.LoadRatesFile(ratesFile); err != nil {
+			return nil, fmt.Errorf("exchange rates: %w", err)
+		}
+	}
+	if unitsFile != "" {
+		if err := registry.LoadDefinitionsFile(unitsFile); err != nil {
+			return nil, fmt.Errorf("unit definitions: %w", err)
+		}
+	}
+	return registry, nil
+}
+
+// reloader rebuilds the registry whenever the rates file changes, so that a
+// new snapshot of exchange rates applies without restarting the server.
+type reloader struct {
+	unitsFile, ratesFile string
+
+	mu           sync.Mutex
+	current      *converter.Registry
+	ratesModTime time.Time
+}
+
+func newReloader(unitsFile, ratesFile string) *reloader {
+	rl := &reloader{unitsFile: unitsFile, ratesFile: ratesFile}
+	if info, err := os.Stat(ratesFile); err == nil {
+		rl.ratesModTime = info.ModTime()
+	}
+	rl.current = loadRegistry(unitsFile, ratesFile)
+	return rl
 }
 
-func startServer(port int) {
-	unitMap := converter.MustRegisterSystems()
-	conv := converter.NewConverter(unitMap)
+// registry returns the current registry. A rates file that fails to load
+// leaves the previous rates in place until it changes again.
+func (rl *reloader) registry() *converter.Registry {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.ratesFile == "" {
+		return rl.current
+	}
+	info, err := os.Stat(rl.ratesFile)
+	if err != nil || info.ModTime().Equal(rl.ratesModTime) {
+		return rl.current
+	}
+	rl.ratesModTime = info.ModTime()
+	registry, err := newRegistry(rl.unitsFile, rl.ratesFile)
+	if err != nil {
+		log.Printf("⚠️ Keeping the previous exchange rates: %v\n", err)
+		return rl.current
+	}
+	rl.current = registry
+	log.Printf("🔄 Reloaded exchange rates as of %s\n", registry.RatesAsOf().Format(time.DateOnly))
+	return rl.current
+}
+
+// newConverter returns a converter for the locale and display settings given
+// on the command line.
+func newConverter(registry *converter.Registry, locale converter.Locale, dpi, fontSize float64) (*converter.Converter, error) {
+	c := converter.NewConverter(registry)
+	c.SetLocale(locale)
+	if err := c.SetDPI(dpi); err != nil {
+		return nil, err
+	}
+	if err := c.SetFontSize(fontSize); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func startServer(port int, unitsFile, ratesFile string, locale converter.Locale, dpi, fontSize float64) {
+	// The display settings are checked once, so requests cannot fail on them
+	if _, err := newConverter(converter.NewRegistry(), locale, dpi, fontSize); err != nil {
+		log.Fatalf("❌ %v\n", err)
+	}
+	registries := newReloader(unitsFile, ratesFile)
+
+	// /units lists the catalog; /units?alias=oz lists the units an alias
+	// refers to.
+	http.HandleFunc("/units", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(APIResponse{Error: "Only GET requests are allowed", Code: "bad_request"})
+			return
+		}
+
+		registry := registries.registry()
+		if alias := r.URL.Query().Get("alias"); alias != "" {
+			units, ok := registry.Lookup(alias)
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(APIResponse{Error: fmt.Sprintf("unknown unit: '%s'", alias), Code: "unknown_unit"})
+				return
+			}
+			infos := make([]UnitInfo, len(units))
+			for i, unit := range units {
+				infos[i] = newUnitInfo(unit)
+			}
+			json.NewEncoder(w).Encode(infos)
+			return
+		}
+
+		units := registry.Units()
+		var systems []SystemInfo
+		for _, system := range registry.Systems() {
+			info := SystemInfo{
+				Name:      system.Name,
+				Dimension: system.Dimension.String(),
+				Kind:      system.Kind,
+				BaseUnit:  system.BaseUnit,
+			}
+			for _, unit := range units {
+				if unit.System == system.Name {
+					info.Units = append(info.Units, newUnitInfo(unit))
+				}
+			}
+			systems = append(systems, info)
+		}
+		json.NewEncoder(w).Encode(systems)
+	})
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		// Only allow GET requests
 		if r.Method != http.MethodGet {
 			w.WriteHeader(http.StatusMethodNotAllowed)
-			json.NewEncoder(w).Encode(APIResponse{Error: "Only GET requests are allowed"})
+			json.NewEncoder(w).Encode(APIResponse{Error: "Only GET requests are allowed", Code: "bad_request"})
 			return
 		}
 
@@ -189,23 +601,42 @@ func startServer(port int) {
 		if len(query) > 100 {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(APIResponse{Error: "Query too long. Maximum 100 characters allowed."})
+			json.NewEncoder(w).Encode(APIResponse{Error: "Query too long. Maximum 100 characters allowed.", Code: "bad_request"})
 			return
 		}
 
 		// Process the conversion
-		result, err := conv.Process(query)
+		c, _ := newConverter(registries.registry(), locale, dpi, fontSize)
+		c.SetExact(r.URL.Query().Get("exact") != "")
+		if name := r.URL.Query().Get("locale"); name != "" {
+			requested, err := converter.ParseLocale(name)
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(APIResponse{Error: err.Error(), Code: "bad_request"})
+				return
+			}
+			c.SetLocale(requested)
+		}
+		result, err := c.Process(query)
 
 		w.Header().Set("Content-Type", "application/json")
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(APIResponse{Error: err.Error()})
+			json.NewEncoder(w).Encode(errorResponse(err))
 		} else {
-			json.NewEncoder(w).Encode(APIResponse{
+			response := APIResponse{
 				Value:      result.Value,
 				UnitSymbol: result.UnitSymbol,
 				UnitName:   result.UnitName,
-			})
+			}
+			if result.Exact != nil {
+				response.Exact = result.Exact.RatString()
+			}
+			if !result.RatesAsOf.IsZero() {
+				response.AsOf = result.RatesAsOf.Format(time.DateOnly)
+			}
+			json.NewEncoder(w).Encode(response)
 		}
 	})
 
@@ -213,6 +644,7 @@ func startServer(port int) {
 	log.Printf("🚀 NLP Unit Converter API Server starting on http://localhost:%d\n", port)
 	log.Printf("📝 Open http://localhost:%d in your browser\n", port)
 	log.Printf("🔧 API endpoint: http://localhost:%d/?q=your+query\n", port)
+	log.Printf("📚 Unit catalog: http://localhost:%d/units\n", port)
 
 	if err := http.ListenAndServe(addr, nil); err != nil {
 		log.Fatalf("Failed to start server on port %d: %v\n", port, err)
@@ -224,10 +656,25 @@ func main() {
 	flag.BoolVar(help, "help", false, "Prints the help message.")
 	serverMode := flag.Bool("ss", false, "Starts a web API server.")
 	flag.BoolVar(serverMode, "start-server", false, "Starts a web API server.")
+	exact := flag.Bool("x", false, "Also prints the exact fractional result.")
+	flag.BoolVar(exact, "exact", false, "Also prints the exact fractional result.")
+	unitsFile := flag.String("u", "", "Loads custom units from a JSON definitions file.")
+	flag.StringVar(unitsFile, "units-file", "", "Loads custom units from a JSON definitions file.")
+	ratesFile := flag.String("r", "", "Loads exchange rates from a JSON or CSV snapshot.")
+	flag.StringVar(ratesFile, "rates-file", "", "Loads exchange rates from a JSON or CSV snapshot.")
+	localeName := flag.String("l", "us", "Reads gallons, pints, fluid ounces and tons as US or UK units.")
+	flag.StringVar(localeName, "locale", "us", "Reads gallons, pints, fluid ounces and tons as US or UK units.")
+	dpi := flag.Float64("dpi", 96, "Sets the pixels per inch of px, em and rem.")
+	fontSize := flag.Float64("font-size", 16, "Sets the size of em and rem in pixels.")
 	flag.Parse()
 
+	locale, err := converter.ParseLocale(*localeName)
+	if err != nil {
+		log.Fatalf("❌ %v\n", err)
+	}
+
 	if *help {
-		printHelp()
+		printHelp(loadRegistry(*unitsFile, *ratesFile))
 		os.Exit(0)
 	}
 
@@ -243,12 +690,15 @@ func main() {
 			}
 		}
 
-		startServer(port)
+		startServer(port, *unitsFile, *ratesFile, locale, *dpi, *fontSize)
 		return
 	}
 
-	unitMap := converter.MustRegisterSystems()
-	conv := converter.NewConverter(unitMap)
+	conv, err := newConverter(loadRegistry(*unitsFile, *ratesFile), locale, *dpi, *fontSize)
+	if err != nil {
+		log.Fatalf("❌ %v\n", err)
+	}
+	conv.SetExact(*exact)
 
 	if len(os.Args) == 1 {
 		fmt.Println("No expression provided. Use -h or --help for usage information.")
@@ -265,8 +715,11 @@ func main() {
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
+	} else if result.Exact != nil {
+		fmt.Printf("%s = %s exactly\n", formatResult(result), result.Exact.RatString())
+		os.Exit(0)
 	} else {
-		fmt.Printf("%g %s (%s)\n", result.Value, result.UnitSymbol, result.UnitName)
+		fmt.Println(formatResult(result))
 		os.Exit(0)
 	}
 }